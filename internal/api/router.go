@@ -1,7 +1,10 @@
 package api
 
 import (
+	"fmt"
+	"html/template"
 	"live-channels/internal/models"
+	"live-channels/internal/platform"
 	"live-channels/internal/service"
 	"net/http"
 	"strconv"
@@ -11,14 +14,18 @@ import (
 )
 
 // SetupRouter 设置路由
-func SetupRouter(cfg *models.Config) *gin.Engine {
+func SetupRouter(streamService *service.StreamService) *gin.Engine {
 	router := gin.Default()
 
 	// 添加 CORS 中间件
 	router.Use(corsMiddleware())
 
-	// 创建服务
-	streamService := service.NewStreamService(cfg)
+	// 注册模板函数（必须在加载模板之前）
+	router.SetFuncMap(template.FuncMap{
+		"liveDuration": func(since int64) string {
+			return formatLiveDuration(since, time.Now())
+		},
+	})
 
 	// 加载 HTML 模板
 	router.LoadHTMLGlob("./web/*.html")
@@ -29,7 +36,7 @@ func SetupRouter(cfg *models.Config) *gin.Engine {
 	// 提供 index.html，并带上主播数据
 	router.GET("/", func(c *gin.Context) {
 		cacheDuration := getCacheDuration(c)
-		statuses, err := streamService.GetAllStreamStatus(cacheDuration)
+		statuses, err := streamService.GetAllStreamStatus(c.Request.Context(), cacheDuration)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.APIResponse{
 				Status:  "error",
@@ -53,7 +60,7 @@ func SetupRouter(cfg *models.Config) *gin.Engine {
 	// 获取所有直播状态
 	router.GET("/api/streams", func(c *gin.Context) {
 		cacheDuration := getCacheDuration(c)
-		statuses, err := streamService.GetAllStreamStatus(cacheDuration)
+		statuses, err := streamService.GetAllStreamStatus(c.Request.Context(), cacheDuration)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.APIResponse{
 				Status:  "error",
@@ -87,7 +94,7 @@ func SetupRouter(cfg *models.Config) *gin.Engine {
 		}
 
 		cacheDuration := getCacheDuration(c)
-		statuses, err := streamService.GetStreamStatusByPlatform(platformType, cacheDuration)
+		statuses, err := streamService.GetStreamStatusByPlatform(c.Request.Context(), platformType, cacheDuration)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.APIResponse{
 				Status:  "error",
@@ -106,10 +113,19 @@ func SetupRouter(cfg *models.Config) *gin.Engine {
 		})
 	})
 
+	// 获取支持的平台列表
+	router.GET("/api/platforms", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status": "success",
+			"data":   platform.Descriptors(),
+		})
+	})
+
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
-			"status": "ok",
+			"status":   "ok",
+			"breakers": streamService.BreakerStatuses(),
 		})
 	})
 
@@ -126,6 +142,25 @@ func getCacheDuration(c *gin.Context) time.Duration {
 	return time.Duration(cacheSeconds) * time.Second
 }
 
+// formatLiveDuration 将开播时间格式化为已直播时长，如 "2h 13m"
+func formatLiveDuration(since int64, now time.Time) string {
+	if since <= 0 {
+		return ""
+	}
+
+	duration := now.Sub(time.Unix(since, 0))
+	if duration < time.Minute {
+		return "<1m"
+	}
+
+	hours := int(duration.Hours())
+	minutes := int(duration.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
 // corsMiddleware CORS 中间件
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
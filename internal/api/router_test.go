@@ -2,11 +2,13 @@ package api
 
 import (
 	"live-channels/internal/models"
+	"live-channels/internal/service"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -32,8 +34,7 @@ func TestMain(m *testing.M) {
 }
 
 func TestHealthCheck(t *testing.T) {
-	cfg := &models.Config{}
-	router := SetupRouter(cfg)
+	router := SetupRouter(service.NewStreamService(&models.Config{}, nil))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/health", nil)
@@ -46,11 +47,14 @@ func TestHealthCheck(t *testing.T) {
 	if !strings.Contains(w.Body.String(), `"status":"ok"`) {
 		t.Errorf("Body does not contain status ok: %v", w.Body.String())
 	}
+
+	if !strings.Contains(w.Body.String(), `"breakers"`) {
+		t.Errorf("Body does not contain breaker states: %v", w.Body.String())
+	}
 }
 
 func TestInvalidPlatformAPI(t *testing.T) {
-	cfg := &models.Config{}
-	router := SetupRouter(cfg)
+	router := SetupRouter(service.NewStreamService(&models.Config{}, nil))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/api/streams/invalid_platform", nil)
@@ -65,6 +69,44 @@ func TestInvalidPlatformAPI(t *testing.T) {
 	}
 }
 
+func TestPlatformsAPI(t *testing.T) {
+	router := SetupRouter(service.NewStreamService(&models.Config{}, nil))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/platforms", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status OK, got %v", w.Code)
+	}
+
+	body := w.Body.String()
+	if !strings.Contains(body, `"name":"bilibili"`) || !strings.Contains(body, `"batch":true`) {
+		t.Errorf("Body does not list registered platforms: %v", body)
+	}
+}
+
+func TestUnavailableChannel(t *testing.T) {
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{{Platform: models.PlatformHuya, ChannelID: "123", Name: "测试主播"}},
+	}
+	router := SetupRouter(service.NewStreamService(cfg, nil))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/streams", nil)
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `"error":{"kind":"unsupported"`) {
+		t.Errorf("API response does not contain error entry: %v", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "测试主播") || !strings.Contains(w.Body.String(), "Unavailable") {
+		t.Errorf("widget does not render unavailable row: %v", w.Body.String())
+	}
+}
+
 func TestGetCacheDuration(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -90,3 +132,26 @@ func TestGetCacheDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatLiveDuration(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		since    int64
+		expected string
+	}{
+		{"Unknown", 0, ""},
+		{"JustStarted", now.Unix() - 30, "<1m"},
+		{"Minutes", now.Unix() - 13*60, "13m"},
+		{"Hours", now.Unix() - (2*3600 + 13*60), "2h 13m"},
+		{"Long", now.Unix() - 26*3600, "26h 0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatLiveDuration(tt.since, now); got != tt.expected {
+				t.Errorf("formatLiveDuration() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
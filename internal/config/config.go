@@ -1,9 +1,13 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"live-channels/internal/models"
+	"live-channels/internal/platform"
 	"os"
+	"path/filepath"
 )
 
 // LoadConfig 从 JSON 文件加载配置
@@ -19,5 +23,40 @@ func LoadConfig(filePath string) (*models.Config, error) {
 		return nil, err
 	}
 
+	if err := resolveChannelURLs(context.Background(), cfg.Channels); err != nil {
+		return nil, err
+	}
+
+	// 开播时段统计默认保存在配置文件旁，相对路径基于配置文件所在目录
+	dir := filepath.Dir(filePath)
+	if cfg.Polling.StateFile == "" {
+		cfg.Polling.StateFile = filepath.Join(dir, "polling_state.json")
+	} else if !filepath.IsAbs(cfg.Polling.StateFile) {
+		cfg.Polling.StateFile = filepath.Join(dir, cfg.Polling.StateFile)
+	}
+
 	return &cfg, nil
 }
+
+// resolveChannelURLs 将配置了 url 的频道解析为 platform 和 channel_id
+// 同时填写了 platform 或 channel_id 时必须与链接解析结果一致
+func resolveChannelURLs(ctx context.Context, channels []models.ChannelConfig) error {
+	for i := range channels {
+		ch := &channels[i]
+		if ch.URL == "" {
+			continue
+		}
+
+		p, channelID, err := platform.ResolveURL(ctx, ch.URL)
+		if err != nil {
+			return fmt.Errorf("channels[%d]: %w", i, err)
+		}
+		if (ch.Platform != "" && ch.Platform != p) || (ch.ChannelID != "" && ch.ChannelID != channelID) {
+			return fmt.Errorf("channels[%d]: url %q resolves to %s/%s, conflicts with %s/%s",
+				i, ch.URL, p, channelID, ch.Platform, ch.ChannelID)
+		}
+		ch.Platform = p
+		ch.ChannelID = channelID
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"live-channels/internal/models"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 )
 
@@ -66,3 +68,91 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadConfigChannelURL(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json")
+	content := `{
+		"channels": [
+			{"url": "https://live.bilibili.com/21013446?spm_id_from=333", "name": "b"},
+			{"url": "https://www.douyu.com/topic/s13?rid=9999", "platform": "douyu"},
+			{"platform": "huya", "channel_id": "lpl"}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := [][2]string{{"bilibili", "21013446"}, {"douyu", "9999"}, {"huya", "lpl"}}
+	for i, ch := range cfg.Channels {
+		if string(ch.Platform) != want[i][0] || ch.ChannelID != want[i][1] {
+			t.Errorf("channels[%d] = %s/%s, want %s/%s", i, ch.Platform, ch.ChannelID, want[i][0], want[i][1])
+		}
+	}
+
+	for name, channel := range map[string]string{
+		"Unsupported URL": `{"url": "https://example.com/123"}`,
+		"Conflict":        `{"url": "https://www.douyu.com/9999", "platform": "huya"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(`{"channels": [`+channel+`]}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfig(path); err == nil {
+				t.Errorf("LoadConfig() should return error")
+			}
+		})
+	}
+}
+
+func TestLoadConfigPlatformOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+		"channels": [],
+		"platforms": {
+			"twitch": {"options": {"client_id": "id", "client_secret": "secret"}, "endpoints": {"oauth": "http://auth.local"}},
+			"youtube": {"options": {"api_key": "key"}, "timeout": 3}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	twitch := cfg.Platforms[models.PlatformTwitch]
+	if twitch.Options["client_id"] != "id" || twitch.Options["client_secret"] != "secret" || twitch.Endpoints["oauth"] != "http://auth.local" {
+		t.Errorf("platforms.twitch = %+v", twitch)
+	}
+	if youtube := cfg.Platforms[models.PlatformYouTube]; youtube.Options["api_key"] != "key" || youtube.Timeout != 3 {
+		t.Errorf("platforms.youtube = %+v", youtube)
+	}
+}
+
+func TestLoadConfigStateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	abs := filepath.Join(t.TempDir(), "hours.json")
+	for content, want := range map[string]string{
+		`{"channels": []}`: filepath.Join(dir, "polling_state.json"),
+		`{"channels": [], "polling": {"state_file": "state/hours.json"}}`:         filepath.Join(dir, "state", "hours.json"),
+		`{"channels": [], "polling": {"state_file": ` + strconv.Quote(abs) + `}}`: abs,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if cfg.Polling.StateFile != want {
+			t.Errorf("StateFile = %q, want %q", cfg.Polling.StateFile, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"live-channels/internal/logger"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// DefaultBufferSize 订阅者默认的事件缓冲数量
+const DefaultBufferSize = 64
+
+// Bus 进程内事件总线
+// Publish 不会阻塞，订阅者缓冲已满时丢弃该订阅者的事件并记录日志
+type Bus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   map[int]chan Event
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{subs: make(map[int]chan Event)}
+}
+
+// Subscribe 订阅所有事件，buffer 小于等于 0 时使用默认缓冲
+// 返回的取消函数会关闭事件通道，可重复调用
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	if buffer <= 0 {
+		buffer = DefaultBufferSize
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	ch := make(chan Event, buffer)
+	b.subs[id] = ch
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish 向所有订阅者发送事件
+func (b *Bus) Publish(e Event) {
+	logger.Debug("Publishing event",
+		zap.String("type", string(e.Type)),
+		zap.String("platform", string(e.Channel.Platform)),
+		zap.String("channel_id", e.Channel.Key()),
+	)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for id, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			logger.Warn("Event subscriber is full, dropping event",
+				zap.Int("subscriber", id),
+				zap.String("type", string(e.Type)),
+			)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestBusPublishSubscribe(t *testing.T) {
+	bus := NewBus()
+	first, cancelFirst := bus.Subscribe(1)
+	second, cancelSecond := bus.Subscribe(1)
+	defer cancelSecond()
+
+	bus.Publish(Event{Type: WentLive})
+	if e := <-first; e.Type != WentLive {
+		t.Errorf("first subscriber got %q, want %q", e.Type, WentLive)
+	}
+	if e := <-second; e.Type != WentLive {
+		t.Errorf("second subscriber got %q, want %q", e.Type, WentLive)
+	}
+
+	// 缓冲已满时丢弃事件而不是阻塞
+	bus.Publish(Event{Type: TitleChanged})
+	bus.Publish(Event{Type: WentOffline})
+	if e := <-second; e.Type != TitleChanged {
+		t.Errorf("second subscriber got %q, want %q", e.Type, TitleChanged)
+	}
+	if len(second) != 0 {
+		t.Errorf("second subscriber should have dropped the overflowing event")
+	}
+
+	// 取消订阅后通道关闭，不再接收事件
+	cancelFirst()
+	cancelFirst()
+	if e := <-first; e.Type != TitleChanged {
+		t.Errorf("buffered event should still be readable after cancel, got %q", e.Type)
+	}
+	if _, ok := <-first; ok {
+		t.Errorf("channel should be closed after cancel")
+	}
+	bus.Publish(Event{Type: WentLive})
+}
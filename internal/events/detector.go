@@ -0,0 +1,118 @@
+package events
+
+import (
+	"live-channels/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultViewerMilestones 默认的观众数里程碑
+var DefaultViewerMilestones = []int{1000, 10000, 100000}
+
+// Detector 比较频道前后两次获取的状态并生成事件
+// 记录每个频道最近一次有效状态，以及本场直播已达到的观众数里程碑
+type Detector struct {
+	milestones []int
+
+	mu      sync.Mutex
+	last    map[string]models.StreamStatus
+	reached map[string]int
+}
+
+// NewDetector 创建事件检测器，milestones 为空时使用默认里程碑
+func NewDetector(milestones []int) *Detector {
+	if len(milestones) == 0 {
+		milestones = DefaultViewerMilestones
+	}
+	sorted := append([]int(nil), milestones...)
+	sort.Ints(sorted)
+
+	return &Detector{
+		milestones: sorted,
+		last:       make(map[string]models.StreamStatus),
+		reached:    make(map[string]int),
+	}
+}
+
+// Observe 记录频道的最新状态，返回与上次状态相比产生的事件
+// 获取失败或旧缓存不参与比较；首次观察到的频道只记录状态，不产生事件
+func (d *Detector) Observe(ch models.ChannelConfig, status models.StreamStatus, now time.Time) []Event {
+	if status.Error != nil || status.Stale {
+		return nil
+	}
+
+	key := string(ch.Platform) + ":" + ch.Key()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	prev, ok := d.last[key]
+	d.last[key] = status
+	if !ok {
+		// 启动时已在直播的频道不补发里程碑
+		if isLive(status) {
+			d.reached[key] = d.highestMilestone(status.Viewers)
+		}
+		return nil
+	}
+
+	newEvent := func(t Type) Event {
+		return Event{Type: t, Channel: ch, Status: status, Previous: prev, Time: now}
+	}
+
+	var events []Event
+	wasLive, live := isLive(prev), isLive(status)
+	switch {
+	case !wasLive && live:
+		d.reached[key] = 0
+		events = append(events, newEvent(WentLive))
+	case wasLive && !live:
+		delete(d.reached, key)
+		return append(events, newEvent(WentOffline))
+	default:
+		if status.Title != prev.Title {
+			events = append(events, newEvent(TitleChanged))
+		}
+		if categoryName(status) != categoryName(prev) {
+			events = append(events, newEvent(CategoryChanged))
+		}
+	}
+
+	if live {
+		// 同一场直播中每个里程碑只触发一次，观众数在阈值附近波动时不会重复通知
+		if milestone := d.highestMilestone(status.Viewers); milestone > d.reached[key] {
+			d.reached[key] = milestone
+			event := newEvent(ViewerMilestone)
+			event.Milestone = milestone
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// highestMilestone 返回观众数已达到的最高里程碑，未达到任何里程碑时返回 0
+func (d *Detector) highestMilestone(viewers int) int {
+	reached := 0
+	for _, m := range d.milestones {
+		if viewers >= m {
+			reached = m
+		}
+	}
+	return reached
+}
+
+// isLive 返回状态是否为直播中，未设置 State 时按 IsLive 判断
+func isLive(status models.StreamStatus) bool {
+	if status.State != "" {
+		return status.State == models.StateLive
+	}
+	return status.IsLive
+}
+
+// categoryName 返回状态的分区名称，没有分区时使用游戏名称
+func categoryName(status models.StreamStatus) string {
+	if status.Category != nil {
+		return status.Category.Name
+	}
+	return status.Game
+}
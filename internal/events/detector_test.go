@@ -0,0 +1,92 @@
+package events
+
+import (
+	"live-channels/internal/models"
+	"testing"
+	"time"
+)
+
+// eventTypes 返回事件类型列表，便于比较
+func eventTypes(events []Event) []Type {
+	types := make([]Type, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestDetectorObserve(t *testing.T) {
+	detector := NewDetector([]int{10000, 1000})
+	ch := models.ChannelConfig{Platform: models.PlatformBilibili, ChannelID: "1"}
+	now := time.Now()
+
+	offline := models.StreamStatus{State: models.StateOffline, Title: "标题"}
+	live := models.StreamStatus{State: models.StateLive, IsLive: true, Title: "标题", Game: "英雄联盟", Viewers: 500}
+
+	steps := []struct {
+		name   string
+		status models.StreamStatus
+		want   []Type
+	}{
+		{"first observation", offline, nil},
+		{"went live", live, []Type{WentLive}},
+		{"unchanged", live, nil},
+		{"title and category", func() models.StreamStatus {
+			s := live
+			s.Title = "新标题"
+			s.Category = &models.Category{Name: "王者荣耀"}
+			return s
+		}(), []Type{TitleChanged, CategoryChanged}},
+		{"fetch error ignored", models.StreamStatus{Error: &models.StreamError{Kind: "upstream"}}, nil},
+		{"stale ignored", models.StreamStatus{State: models.StateOffline, Stale: true}, nil},
+		{"milestone", func() models.StreamStatus {
+			s := live
+			s.Title = "新标题"
+			s.Category = &models.Category{Name: "王者荣耀"}
+			s.Viewers = 1200
+			return s
+		}(), []Type{ViewerMilestone}},
+		{"milestone not repeated", func() models.StreamStatus {
+			s := live
+			s.Title = "新标题"
+			s.Category = &models.Category{Name: "王者荣耀"}
+			s.Viewers = 1100
+			return s
+		}(), nil},
+		{"replay counts as offline", models.StreamStatus{State: models.StateReplay}, []Type{WentOffline}},
+	}
+
+	for _, step := range steps {
+		got := eventTypes(detector.Observe(ch, step.status, now))
+		if len(got) != len(step.want) {
+			t.Fatalf("%s: events = %v, want %v", step.name, got, step.want)
+		}
+		for i := range got {
+			if got[i] != step.want[i] {
+				t.Fatalf("%s: events = %v, want %v", step.name, got, step.want)
+			}
+		}
+	}
+}
+
+func TestDetectorMilestoneEvent(t *testing.T) {
+	detector := NewDetector(nil)
+	ch := models.ChannelConfig{Platform: models.PlatformHuya, ChannelID: "lpl"}
+	now := time.Now()
+
+	detector.Observe(ch, models.StreamStatus{State: models.StateOffline}, now)
+	events := detector.Observe(ch, models.StreamStatus{State: models.StateLive, Viewers: 20000}, now)
+	if len(events) != 2 || events[0].Type != WentLive || events[1].Type != ViewerMilestone || events[1].Milestone != 10000 {
+		t.Fatalf("events = %+v", events)
+	}
+	if events[0].Previous.State != models.StateOffline || events[0].Channel.ChannelID != "lpl" {
+		t.Errorf("event Previous/Channel = %+v/%+v", events[0].Previous, events[0].Channel)
+	}
+
+	// 启动时已在直播的频道不补发已达到的里程碑
+	other := models.ChannelConfig{Platform: models.PlatformHuya, ChannelID: "other"}
+	detector.Observe(other, models.StreamStatus{State: models.StateLive, Viewers: 20000}, now)
+	if events := detector.Observe(other, models.StreamStatus{State: models.StateLive, Viewers: 25000}, now); len(events) != 0 {
+		t.Errorf("events for channel already past milestone = %+v", events)
+	}
+}
@@ -0,0 +1,27 @@
+package events
+
+import (
+	"live-channels/internal/models"
+	"time"
+)
+
+// Type 事件类型
+type Type string
+
+const (
+	WentLive        Type = "went_live"        // 开播
+	WentOffline     Type = "went_offline"     // 下播（包括转为轮播）
+	TitleChanged    Type = "title_changed"    // 直播标题变化
+	CategoryChanged Type = "category_changed" // 直播分区变化
+	ViewerMilestone Type = "viewer_milestone" // 本场直播观众数首次达到里程碑
+)
+
+// Event 频道状态变化事件
+type Event struct {
+	Type      Type                 `json:"type"`
+	Channel   models.ChannelConfig `json:"channel"`             // 触发事件的频道配置
+	Status    models.StreamStatus  `json:"status"`              // 变化后的状态
+	Previous  models.StreamStatus  `json:"previous"`            // 变化前的状态
+	Milestone int                  `json:"milestone,omitempty"` // 达到的观众数里程碑，仅 viewer_milestone 事件
+	Time      time.Time            `json:"time"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "sync"
+
 // Platform 直播平台类型
 type Platform string
 
@@ -7,43 +9,192 @@ const (
 	PlatformBilibili Platform = "bilibili"
 	PlatformDouyu    Platform = "douyu"
 	PlatformHuya     Platform = "huya"
+	PlatformDouyin   Platform = "douyin"
+	PlatformKuaishou Platform = "kuaishou"
+	PlatformCC       Platform = "cc"
+	PlatformTwitch   Platform = "twitch"
+	PlatformYouTube  Platform = "youtube"
+	PlatformAcFun    Platform = "acfun"
+)
+
+var (
+	platformsMu sync.RWMutex
+	platforms   = make(map[Platform]struct{})
 )
 
-// IsValid 验证平台是否有效
+// RegisterPlatform 登记可用平台，由 platform.Register 在注册时调用
+func RegisterPlatform(p Platform) {
+	platformsMu.Lock()
+	defer platformsMu.Unlock()
+	platforms[p] = struct{}{}
+}
+
+// IsValid 验证平台是否已注册
 func (p Platform) IsValid() bool {
-	switch p {
-	case PlatformBilibili, PlatformDouyu, PlatformHuya:
-		return true
-	}
-	return false
+	platformsMu.RLock()
+	defer platformsMu.RUnlock()
+	_, ok := platforms[p]
+	return ok
 }
 
 // ChannelConfig 频道配置
 type ChannelConfig struct {
+	URL       string   `json:"url,omitempty"` // 直播间链接，加载配置时解析为 platform 和 channel_id
 	Platform  Platform `json:"platform"`
 	ChannelID string   `json:"channel_id"`
+	UID       string   `json:"uid,omitempty"` // 用户 UID，未填写 channel_id 时使用（目前仅 B 站支持）
 	Name      string   `json:"name"`
+	Interval  int      `json:"interval,omitempty"` // 该频道的轮询间隔（秒），覆盖全局配置
+}
+
+// Key 返回频道在平台内的唯一标识，未配置 channel_id 时使用 "uid:{uid}"
+func (c ChannelConfig) Key() string {
+	if c.ChannelID == "" && c.UID != "" {
+		return "uid:" + c.UID
+	}
+	return c.ChannelID
+}
+
+// PlatformConfig 单个平台的客户端配置，未填写的字段使用默认值
+type PlatformConfig struct {
+	BaseURL    string            `json:"base_url,omitempty"`    // 平台主接口地址，用于自建代理或镜像
+	Timeout    int               `json:"timeout,omitempty"`     // 请求超时（秒），默认 5
+	RetryCount *int              `json:"retry_count,omitempty"` // 失败重试次数，默认 2
+	UserAgent  string            `json:"user_agent,omitempty"`  // 覆盖全局 User-Agent
+	Cookies    map[string]string `json:"cookies,omitempty"`
+	Proxy      string            `json:"proxy,omitempty"` // 例如 http://127.0.0.1:7890 或 socks5://127.0.0.1:1080
+
+	// 平台的其他接口地址，名称由各平台在注册时声明，如虎牙的 mobile、Twitch 的 oauth
+	Endpoints map[string]string `json:"endpoints,omitempty"`
+	// 平台专属选项，名称由各平台在注册时声明，如 Twitch 的 client_id、YouTube 的 api_key
+	Options map[string]string `json:"options,omitempty"`
+
+	// 按目标主机限流，0 使用默认值，负数表示不限制
+	RateLimit     float64 `json:"rate_limit,omitempty"`     // 每秒请求数，默认 5
+	Burst         int     `json:"burst,omitempty"`          // 突发请求数，默认 5
+	MaxConcurrent int     `json:"max_concurrent,omitempty"` // 最大并发请求数，默认 3
+}
+
+// PollingConfig 后台轮询配置
+type PollingConfig struct {
+	Interval int `json:"interval,omitempty"` // 轮询间隔（秒），默认 60
+	Jitter   int `json:"jitter,omitempty"`   // 随机抖动（秒），默认为间隔的 10%，负数表示不抖动
+
+	// 自适应轮询：直播中和临近常规开播时段使用快速间隔，长期未开播的频道逐步退避到慢速间隔
+	// 单独配置了 interval 的频道不参与自适应
+	Adaptive     bool `json:"adaptive,omitempty"`
+	FastInterval int  `json:"fast_interval,omitempty"` // 快速轮询间隔（秒），默认 30
+	SlowInterval int  `json:"slow_interval,omitempty"` // 最长轮询间隔（秒），默认 600
+	// 开播时段统计的保存路径，相对路径基于配置文件所在目录，默认为配置文件旁的 polling_state.json
+	StateFile string `json:"state_file,omitempty"`
+}
+
+// EventsConfig 频道事件配置
+type EventsConfig struct {
+	ViewerMilestones []int `json:"viewer_milestones,omitempty"` // 观众数里程碑，默认 1000、10000、100000
+}
+
+// NotificationsConfig 事件通知配置
+type NotificationsConfig struct {
+	Webhooks []WebhookConfig  `json:"webhooks,omitempty"`
+	Telegram []TelegramConfig `json:"telegram,omitempty"`
+}
+
+// WebhookConfig 单个 Webhook 通知配置
+type WebhookConfig struct {
+	Name       string            `json:"name,omitempty"`   // 用于日志，默认使用 URL 的主机名
+	URL        string            `json:"url"`              // 请求地址
+	Method     string            `json:"method,omitempty"` // 请求方法，默认 POST
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`        // text/template 模板，数据为事件；为空时发送事件 JSON
+	Filter     EventFilter       `json:"filter"`                // 只发送匹配的事件
+	Timeout    int               `json:"timeout,omitempty"`     // 请求超时（秒），默认 10
+	RetryCount *int              `json:"retry_count,omitempty"` // 失败重试次数，默认 3
+}
+
+// TelegramConfig Telegram 机器人通知配置
+// 开播时发送带封面的消息，下播时编辑或删除该消息
+type TelegramConfig struct {
+	BotToken   string      `json:"bot_token"`
+	ChatID     string      `json:"chat_id"`                // 用户、群组 ID 或 @频道用户名
+	APIBaseURL string      `json:"api_base_url,omitempty"` // Bot API 地址，默认 https://api.telegram.org
+	OnOffline  string      `json:"on_offline,omitempty"`   // 下播时的处理方式：edit（默认）或 delete
+	Filter     EventFilter `json:"filter"`                 // 只通知匹配的频道和平台，events 字段不生效
+	Timeout    int         `json:"timeout,omitempty"`      // 请求超时（秒），默认 10
+}
+
+// EventFilter 事件过滤条件，为空的字段表示不过滤
+type EventFilter struct {
+	Events    []string   `json:"events,omitempty"`    // 事件类型，如 went_live
+	Platforms []Platform `json:"platforms,omitempty"` // 平台
+	Channels  []string   `json:"channels,omitempty"`  // 频道 channel_id、"uid:{uid}" 或配置的 name
 }
 
 // Config 应用配置
 type Config struct {
-	Channels  []ChannelConfig `json:"channels"`
-	UserAgent string          `json:"user_agent"`
+	Channels      []ChannelConfig             `json:"channels"`
+	UserAgent     string                      `json:"user_agent"`
+	Platforms     map[Platform]PlatformConfig `json:"platforms,omitempty"`
+	Polling       PollingConfig               `json:"polling"`
+	Events        EventsConfig                `json:"events"`
+	Notifications NotificationsConfig         `json:"notifications"`
+}
+
+// StreamState 直播状态
+type StreamState string
+
+const (
+	StateLive    StreamState = "live"
+	StateOffline StreamState = "offline"
+	StateReplay  StreamState = "replay" // 轮播/重播，主播本人未在直播
+)
+
+// StateFromLive 根据是否在直播返回对应状态，用于没有轮播概念的平台
+func StateFromLive(isLive bool) StreamState {
+	if isLive {
+		return StateLive
+	}
+	return StateOffline
+}
+
+// Category 直播分区
+type Category struct {
+	Name   string `json:"name"`             // 分区名称（二级分区）
+	Parent string `json:"parent,omitempty"` // 上级分区名称
+	URL    string `json:"url,omitempty"`    // 分区页面链接
 }
 
 // StreamStatus 直播状态
 type StreamStatus struct {
-	ChannelID    string `json:"channel_id"`
-	Name         string `json:"name"`
-	Platform     string `json:"platform"`
-	IsLive       bool   `json:"is_live"`
-	Title        string `json:"title"`
-	Game         string `json:"game"`
-	Viewers      int    `json:"viewers"`
-	ThumbnailURL string `json:"thumbnail_url"`
-	AvatarURL    string `json:"avatar_url"`
-	ProfileURL   string `json:"profile_url"`
-	UpdatedAt    int64  `json:"updated_at"`
+	ChannelID    string       `json:"channel_id"`
+	Name         string       `json:"name"`
+	Platform     string       `json:"platform"`
+	IsLive       bool         `json:"is_live"` // 兼容字段，等价于 State == "live"
+	State        StreamState  `json:"state"`
+	Title        string       `json:"title"`
+	Game         string       `json:"game"`
+	Category     *Category    `json:"category,omitempty"`
+	Viewers      int          `json:"viewers"`
+	ViewersText  string       `json:"viewers_text,omitempty"` // 平台自身的展示文本（如 "12.3万"），没有时为空
+	LiveSince    int64        `json:"live_since,omitempty"`   // 开播时间（Unix 时间戳），未开播或未知时为 0
+	ThumbnailURL string       `json:"thumbnail_url"`
+	AvatarURL    string       `json:"avatar_url"`
+	ProfileURL   string       `json:"profile_url"`
+	UpdatedAt    int64        `json:"updated_at"`
+	Stale        bool         `json:"stale,omitempty"` // 获取失败或平台熔断时返回的旧缓存
+	Error        *StreamError `json:"error,omitempty"` // 获取失败且没有缓存时的错误信息
+}
+
+// StreamError 频道获取失败的原因
+type StreamError struct {
+	Kind    string `json:"kind"` // not_found, rate_limited, blocked, parse, config, upstream, timeout, circuit_open, unsupported, pending
+	Message string `json:"message"`
+}
+
+// SetState 设置直播状态，并同步兼容字段 IsLive
+func (s *StreamStatus) SetState(state StreamState) {
+	s.State = state
+	s.IsLive = state == StateLive
 }
 
 // APIResponse API 响应
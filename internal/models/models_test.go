@@ -5,6 +5,11 @@ import (
 )
 
 func TestPlatformValidation(t *testing.T) {
+	// 平台由 platform 包在 init() 中注册，models 包的测试需要手动登记
+	for _, p := range []Platform{PlatformBilibili, PlatformDouyu, PlatformHuya, PlatformDouyin, PlatformKuaishou, PlatformCC, PlatformTwitch, PlatformYouTube, PlatformAcFun} {
+		RegisterPlatform(p)
+	}
+
 	tests := []struct {
 		platform Platform
 		expected bool
@@ -12,6 +17,12 @@ func TestPlatformValidation(t *testing.T) {
 		{PlatformBilibili, true},
 		{PlatformDouyu, true},
 		{PlatformHuya, true},
+		{PlatformDouyin, true},
+		{PlatformKuaishou, true},
+		{PlatformCC, true},
+		{PlatformTwitch, true},
+		{PlatformYouTube, true},
+		{PlatformAcFun, true},
 		{Platform("invalid"), false},
 		{Platform(""), false},
 	}
@@ -22,3 +33,39 @@ func TestPlatformValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestChannelConfigKey(t *testing.T) {
+	tests := []struct {
+		channel  ChannelConfig
+		expected string
+	}{
+		{ChannelConfig{ChannelID: "21013446"}, "21013446"},
+		{ChannelConfig{UID: "123456"}, "uid:123456"},
+		{ChannelConfig{ChannelID: "21013446", UID: "123456"}, "21013446"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.channel.Key(); got != tt.expected {
+			t.Errorf("ChannelConfig%+v.Key() = %q; want %q", tt.channel, got, tt.expected)
+		}
+	}
+}
+
+func TestStreamStatusSetState(t *testing.T) {
+	tests := []struct {
+		state  StreamState
+		isLive bool
+	}{
+		{StateLive, true},
+		{StateReplay, false},
+		{StateOffline, false},
+	}
+
+	for _, tt := range tests {
+		status := &StreamStatus{}
+		status.SetState(tt.state)
+		if status.State != tt.state || status.IsLive != tt.isLive {
+			t.Errorf("SetState(%q) => State=%q IsLive=%v; want IsLive=%v", tt.state, status.State, status.IsLive, tt.isLive)
+		}
+	}
+}
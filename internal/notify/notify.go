@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"context"
+	"live-channels/internal/events"
+	"live-channels/internal/logger"
+	"live-channels/internal/models"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// Notifier 事件通知渠道
+type Notifier interface {
+	// Name 返回渠道名称，用于日志
+	Name() string
+	// Notify 发送一个事件，不需要通知的事件直接返回 nil
+	Notify(ctx context.Context, e events.Event) error
+}
+
+// notifierQueueSize 每个通知渠道的待发送事件数量
+const notifierQueueSize = 64
+
+// Dispatcher 订阅事件总线，将事件按顺序分发给各通知渠道
+// 每个渠道使用独立的队列和 Goroutine，某个渠道发送缓慢或重试时不影响其他渠道
+type Dispatcher struct {
+	notifiers []Notifier
+}
+
+// NewDispatcher 创建事件分发器
+func NewDispatcher(notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{notifiers: notifiers}
+}
+
+// Start 订阅事件总线并开始分发，ctx 取消后停止
+func (d *Dispatcher) Start(ctx context.Context, bus *events.Bus) {
+	if len(d.notifiers) == 0 {
+		return
+	}
+
+	queues := make([]chan events.Event, len(d.notifiers))
+	for i, n := range d.notifiers {
+		queues[i] = make(chan events.Event, notifierQueueSize)
+		go d.deliver(ctx, n, queues[i])
+	}
+
+	sub, cancel := bus.Subscribe(0)
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-sub:
+				for i, queue := range queues {
+					select {
+					case queue <- e:
+					default:
+						logger.Warn("Notification queue is full, dropping event",
+							zap.String("notifier", d.notifiers[i].Name()),
+							zap.String("type", string(e.Type)),
+						)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// deliver 依次发送队列中的事件并记录结果
+func (d *Dispatcher) deliver(ctx context.Context, n Notifier, queue <-chan events.Event) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-queue:
+			if err := n.Notify(ctx, e); err != nil {
+				logger.Error("Notification failed",
+					zap.String("notifier", n.Name()),
+					zap.String("type", string(e.Type)),
+					zap.String("platform", string(e.Channel.Platform)),
+					zap.String("channel_id", e.Channel.Key()),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// matchFilter 判断事件是否匹配过滤条件
+func matchFilter(f models.EventFilter, e events.Event) bool {
+	if len(f.Events) > 0 && !slices.Contains(f.Events, string(e.Type)) {
+		return false
+	}
+	if len(f.Platforms) > 0 && !slices.Contains(f.Platforms, e.Channel.Platform) {
+		return false
+	}
+	if len(f.Channels) > 0 &&
+		!slices.Contains(f.Channels, e.Channel.Key()) &&
+		!(e.Channel.Name != "" && slices.Contains(f.Channels, e.Channel.Name)) {
+		return false
+	}
+	return true
+}
+
+// FromConfig 根据配置创建所有通知渠道
+func FromConfig(cfg models.NotificationsConfig) ([]Notifier, error) {
+	var notifiers []Notifier
+	for _, webhookCfg := range cfg.Webhooks {
+		webhook, err := NewWebhook(webhookCfg)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, webhook)
+	}
+	for _, telegramCfg := range cfg.Telegram {
+		telegram, err := NewTelegram(telegramCfg)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, telegram)
+	}
+	return notifiers, nil
+}
@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"live-channels/internal/events"
+	"live-channels/internal/models"
+	"testing"
+	"time"
+)
+
+func TestMatchFilter(t *testing.T) {
+	e := events.Event{
+		Type:    events.WentLive,
+		Channel: models.ChannelConfig{Platform: models.PlatformBilibili, ChannelID: "21013446", Name: "主播"},
+	}
+
+	tests := []struct {
+		name   string
+		filter models.EventFilter
+		want   bool
+	}{
+		{"empty filter", models.EventFilter{}, true},
+		{"event type", models.EventFilter{Events: []string{"went_live"}}, true},
+		{"other event type", models.EventFilter{Events: []string{"went_offline"}}, false},
+		{"platform", models.EventFilter{Platforms: []models.Platform{models.PlatformBilibili}}, true},
+		{"other platform", models.EventFilter{Platforms: []models.Platform{models.PlatformHuya}}, false},
+		{"channel id", models.EventFilter{Channels: []string{"21013446"}}, true},
+		{"channel name", models.EventFilter{Channels: []string{"主播"}}, true},
+		{"other channel", models.EventFilter{Channels: []string{"1"}}, false},
+		{"all fields", models.EventFilter{Events: []string{"went_live"}, Platforms: []models.Platform{models.PlatformBilibili}, Channels: []string{"主播"}}, true},
+	}
+	for _, tt := range tests {
+		if got := matchFilter(tt.filter, e); got != tt.want {
+			t.Errorf("%s: matchFilter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// recordingNotifier 测试用通知渠道，将收到的事件写入通道
+type recordingNotifier struct {
+	received chan events.Event
+}
+
+func (n *recordingNotifier) Name() string { return "recording" }
+
+func (n *recordingNotifier) Notify(ctx context.Context, e events.Event) error {
+	n.received <- e
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	bus := events.NewBus()
+	notifier := &recordingNotifier{received: make(chan events.Event, 2)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	NewDispatcher(notifier).Start(ctx, bus)
+
+	bus.Publish(events.Event{Type: events.WentLive})
+	bus.Publish(events.Event{Type: events.WentOffline})
+
+	for _, want := range []events.Type{events.WentLive, events.WentOffline} {
+		select {
+		case e := <-notifier.received:
+			if e.Type != want {
+				t.Errorf("received %q, want %q", e.Type, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	notifiers, err := FromConfig(models.NotificationsConfig{
+		Webhooks: []models.WebhookConfig{{URL: "https://example.com/hook"}},
+	})
+	if err != nil || len(notifiers) != 1 || notifiers[0].Name() != "example.com" {
+		t.Errorf("FromConfig() = %v, %v", notifiers, err)
+	}
+
+	if _, err := FromConfig(models.NotificationsConfig{
+		Webhooks: []models.WebhookConfig{{URL: "example.com/hook"}},
+	}); err == nil {
+		t.Errorf("FromConfig() with invalid url should return error")
+	}
+	if _, err := FromConfig(models.NotificationsConfig{
+		Webhooks: []models.WebhookConfig{{URL: "https://example.com/hook", Body: "{{ .Status.Name"}},
+	}); err == nil {
+		t.Errorf("FromConfig() with invalid template should return error")
+	}
+}
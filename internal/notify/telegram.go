@@ -0,0 +1,269 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"live-channels/internal/events"
+	"live-channels/internal/logger"
+	"live-channels/internal/models"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// TelegramAPIBaseURL Telegram Bot API 默认地址
+const TelegramAPIBaseURL = "https://api.telegram.org"
+
+// 下播时对开播消息的处理方式
+const (
+	telegramOfflineEdit   = "edit"
+	telegramOfflineDelete = "delete"
+)
+
+// defaultTelegramRetryCount Telegram 请求失败重试次数
+const defaultTelegramRetryCount = 2
+
+// TelegramResponse Telegram Bot API 响应
+type TelegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Result      struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"result"`
+}
+
+// telegramMessage 已发送的开播消息
+type telegramMessage struct {
+	id    int64
+	photo bool // 是否为图片消息，编辑时需调用 editMessageCaption
+}
+
+// Telegram 通过 Telegram 机器人发送开播通知
+type Telegram struct {
+	cfg     models.TelegramConfig
+	baseURL string
+	client  *resty.Client
+
+	mu       sync.Mutex
+	messages map[string]telegramMessage // 各频道当前直播的开播消息
+}
+
+// NewTelegram 根据配置创建 Telegram 通知渠道
+func NewTelegram(cfg models.TelegramConfig) (*Telegram, error) {
+	if cfg.BotToken == "" || cfg.ChatID == "" {
+		return nil, errors.New("telegram notifier requires bot_token and chat_id")
+	}
+	switch cfg.OnOffline {
+	case "", telegramOfflineEdit, telegramOfflineDelete:
+	default:
+		return nil, fmt.Errorf("invalid telegram on_offline %q, want edit or delete", cfg.OnOffline)
+	}
+
+	baseURL := TelegramAPIBaseURL
+	if cfg.APIBaseURL != "" {
+		u, err := url.Parse(cfg.APIBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid telegram api_base_url %q", cfg.APIBaseURL)
+		}
+		baseURL = strings.TrimRight(cfg.APIBaseURL, "/")
+	}
+
+	timeout := defaultWebhookTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	client := resty.New().
+		SetTimeout(timeout).
+		SetRetryCount(defaultTelegramRetryCount).
+		SetRetryWaitTime(webhookRetryWaitTime).
+		SetRetryMaxWaitTime(webhookRetryMaxWaitTime).
+		AddRetryCondition(func(r *resty.Response, err error) bool {
+			return err != nil || r.StatusCode() >= http.StatusInternalServerError || r.StatusCode() == http.StatusTooManyRequests
+		})
+
+	return &Telegram{
+		cfg:      cfg,
+		baseURL:  baseURL,
+		client:   client,
+		messages: make(map[string]telegramMessage),
+	}, nil
+}
+
+// Name 返回渠道名称，不包含 Token
+func (t *Telegram) Name() string {
+	return "telegram:" + t.cfg.ChatID
+}
+
+// Notify 开播时发送消息，下播时编辑或删除该消息，其他事件忽略
+func (t *Telegram) Notify(ctx context.Context, e events.Event) error {
+	// 开播和下播必须成对处理，只按频道和平台过滤
+	filter := t.cfg.Filter
+	filter.Events = nil
+	if !matchFilter(filter, e) {
+		return nil
+	}
+
+	key := string(e.Channel.Platform) + ":" + e.Channel.Key()
+	switch e.Type {
+	case events.WentLive:
+		msg, err := t.sendLive(ctx, e.Status)
+		if err != nil {
+			return err
+		}
+		t.mu.Lock()
+		t.messages[key] = msg
+		t.mu.Unlock()
+		logger.Info("Telegram message sent",
+			zap.String("chat_id", t.cfg.ChatID),
+			zap.String("channel_id", e.Channel.Key()),
+			zap.Int64("message_id", msg.id),
+		)
+	case events.WentOffline:
+		t.mu.Lock()
+		msg, ok := t.messages[key]
+		delete(t.messages, key)
+		t.mu.Unlock()
+		if !ok {
+			return nil
+		}
+		if t.cfg.OnOffline == telegramOfflineDelete {
+			_, err := t.call(ctx, "deleteMessage", map[string]interface{}{
+				"chat_id":    t.cfg.ChatID,
+				"message_id": msg.id,
+			})
+			return err
+		}
+		// 下播后部分平台不再返回标题，使用开播时的状态生成内容
+		return t.editOffline(ctx, msg, e.Previous)
+	}
+	return nil
+}
+
+// sendLive 发送开播消息，有封面时发送图片，封面无法发送时退回纯文本消息
+func (t *Telegram) sendLive(ctx context.Context, status models.StreamStatus) (telegramMessage, error) {
+	text := liveCaption(status)
+	if status.ThumbnailURL != "" {
+		resp, err := t.call(ctx, "sendPhoto", map[string]interface{}{
+			"chat_id":    t.cfg.ChatID,
+			"photo":      status.ThumbnailURL,
+			"caption":    text,
+			"parse_mode": "HTML",
+		})
+		if err == nil {
+			return telegramMessage{id: resp.Result.MessageID, photo: true}, nil
+		}
+		// Telegram 无法下载封面时返回 400，改为发送文本消息
+		if resp == nil || resp.ErrorCode != http.StatusBadRequest {
+			return telegramMessage{}, err
+		}
+		logger.Warn("Telegram sendPhoto rejected, falling back to text message",
+			zap.String("chat_id", t.cfg.ChatID),
+			zap.Error(err),
+		)
+	}
+
+	resp, err := t.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id":    t.cfg.ChatID,
+		"text":       text,
+		"parse_mode": "HTML",
+	})
+	if err != nil {
+		return telegramMessage{}, err
+	}
+	return telegramMessage{id: resp.Result.MessageID}, nil
+}
+
+// editOffline 将开播消息修改为已下播
+func (t *Telegram) editOffline(ctx context.Context, msg telegramMessage, status models.StreamStatus) error {
+	params := map[string]interface{}{
+		"chat_id":    t.cfg.ChatID,
+		"message_id": msg.id,
+		"parse_mode": "HTML",
+	}
+	method := "editMessageText"
+	if msg.photo {
+		method = "editMessageCaption"
+		params["caption"] = offlineCaption(status)
+	} else {
+		params["text"] = offlineCaption(status)
+	}
+	_, err := t.call(ctx, method, params)
+	return err
+}
+
+// call 调用 Bot API，接口返回 ok=false 时返回错误，此时响应仍会返回以便判断错误码
+func (t *Telegram) call(ctx context.Context, method string, params map[string]interface{}) (*TelegramResponse, error) {
+	resp, err := t.client.R().
+		SetContext(ctx).
+		SetBody(params).
+		Post(fmt.Sprintf("%s/bot%s/%s", t.baseURL, t.cfg.BotToken, method))
+	if err != nil {
+		// 请求地址中包含 Token，避免写入日志
+		return nil, fmt.Errorf("telegram %s: %s", method, strings.ReplaceAll(err.Error(), t.cfg.BotToken, "<token>"))
+	}
+
+	var result TelegramResponse
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("telegram %s: %s: failed to parse response: %w", method, resp.Status(), err)
+	}
+	if !result.OK {
+		return &result, fmt.Errorf("telegram %s: %s: %s", method, resp.Status(), result.Description)
+	}
+	return &result, nil
+}
+
+// liveCaption 生成开播消息内容
+func liveCaption(status models.StreamStatus) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔴 <b>%s</b> is live\n", html.EscapeString(status.Name))
+	if status.Title != "" {
+		b.WriteString(html.EscapeString(status.Title) + "\n")
+	}
+
+	var details []string
+	if category := categoryText(status); category != "" {
+		details = append(details, html.EscapeString(category))
+	}
+	viewers := status.ViewersText
+	if viewers == "" {
+		viewers = strconv.Itoa(status.Viewers)
+	}
+	details = append(details, html.EscapeString(viewers)+" viewers")
+	b.WriteString(strings.Join(details, " · ") + "\n")
+
+	if status.ProfileURL != "" {
+		fmt.Fprintf(&b, `<a href="%s">Watch</a>`, html.EscapeString(status.ProfileURL))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// offlineCaption 生成下播后的消息内容
+func offlineCaption(status models.StreamStatus) string {
+	text := fmt.Sprintf("⚫ <b>%s</b> stream ended", html.EscapeString(status.Name))
+	if status.Title != "" {
+		text += "\n" + html.EscapeString(status.Title)
+	}
+	return text
+}
+
+// categoryText 返回分区文本，如 "英雄联盟 › 网游竞技"
+func categoryText(status models.StreamStatus) string {
+	if status.Category == nil {
+		return status.Game
+	}
+	if status.Category.Parent != "" {
+		return status.Category.Name + " › " + status.Category.Parent
+	}
+	return status.Category.Name
+}
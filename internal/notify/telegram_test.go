@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"live-channels/internal/events"
+	"live-channels/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// telegramStub 模拟 Bot API，记录调用的方法和参数
+type telegramStub struct {
+	mu        sync.Mutex
+	calls     []string
+	params    []map[string]interface{}
+	failPhoto bool
+}
+
+func (s *telegramStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if !strings.HasPrefix(r.URL.Path, "/bottest-token/") {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"ok":false,"error_code":404,"description":"Not Found"}`))
+		return
+	}
+
+	var params map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&params)
+	s.mu.Lock()
+	s.calls = append(s.calls, method)
+	s.params = append(s.params, params)
+	s.mu.Unlock()
+
+	if method == "sendPhoto" && s.failPhoto {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`))
+		return
+	}
+	w.Write([]byte(`{"ok":true,"result":{"message_id":42}}`))
+}
+
+func newTestTelegram(t *testing.T, stub *telegramStub, onOffline string) *Telegram {
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	telegram, err := NewTelegram(models.TelegramConfig{
+		BotToken:   "test-token",
+		ChatID:     "-100123",
+		APIBaseURL: server.URL + "/",
+		OnOffline:  onOffline,
+		Filter:     models.EventFilter{Events: []string{"went_live"}, Platforms: []models.Platform{models.PlatformBilibili}},
+	})
+	if err != nil {
+		t.Fatalf("NewTelegram() error = %v", err)
+	}
+	return telegram
+}
+
+func TestTelegramLiveThenEdit(t *testing.T) {
+	stub := &telegramStub{}
+	telegram := newTestTelegram(t, stub, "")
+	ch := models.ChannelConfig{Platform: models.PlatformBilibili, ChannelID: "1"}
+	live := models.StreamStatus{
+		Name:         "<主播>",
+		Title:        "今晚 & 明晚",
+		Category:     &models.Category{Name: "英雄联盟", Parent: "网游"},
+		Viewers:      123000,
+		ViewersText:  "12.3万",
+		ThumbnailURL: "https://example.com/cover.jpg",
+		ProfileURL:   "https://live.bilibili.com/1",
+	}
+
+	ctx := context.Background()
+	if err := telegram.Notify(ctx, events.Event{Type: events.WentLive, Channel: ch, Status: live}); err != nil {
+		t.Fatalf("Notify(went_live) error = %v", err)
+	}
+	// filter 中的 events 不影响下播处理
+	if err := telegram.Notify(ctx, events.Event{Type: events.WentOffline, Channel: ch, Status: models.StreamStatus{Name: "<主播>"}, Previous: live}); err != nil {
+		t.Fatalf("Notify(went_offline) error = %v", err)
+	}
+	// 其他平台的频道不通知
+	other := models.ChannelConfig{Platform: models.PlatformHuya, ChannelID: "1"}
+	if err := telegram.Notify(ctx, events.Event{Type: events.WentLive, Channel: other, Status: live}); err != nil {
+		t.Fatalf("Notify() for filtered channel error = %v", err)
+	}
+
+	if strings.Join(stub.calls, ",") != "sendPhoto,editMessageCaption" {
+		t.Fatalf("calls = %v", stub.calls)
+	}
+	photo := stub.params[0]
+	caption, _ := photo["caption"].(string)
+	if photo["chat_id"] != "-100123" || photo["photo"] != "https://example.com/cover.jpg" || photo["parse_mode"] != "HTML" {
+		t.Errorf("sendPhoto params = %v", photo)
+	}
+	for _, want := range []string{"&lt;主播&gt;", "今晚 &amp; 明晚", "英雄联盟 › 网游", "12.3万 viewers", `<a href="https://live.bilibili.com/1">`} {
+		if !strings.Contains(caption, want) {
+			t.Errorf("caption %q should contain %q", caption, want)
+		}
+	}
+
+	edit := stub.params[1]
+	if edit["message_id"] != float64(42) || !strings.Contains(edit["caption"].(string), "stream ended") || !strings.Contains(edit["caption"].(string), "今晚 &amp; 明晚") {
+		t.Errorf("editMessageCaption params = %v", edit)
+	}
+}
+
+func TestTelegramFallbackAndDelete(t *testing.T) {
+	stub := &telegramStub{failPhoto: true}
+	telegram := newTestTelegram(t, stub, "delete")
+	ch := models.ChannelConfig{Platform: models.PlatformBilibili, ChannelID: "1"}
+	ctx := context.Background()
+
+	live := models.StreamStatus{Name: "主播", ThumbnailURL: "https://example.com/cover.jpg"}
+	if err := telegram.Notify(ctx, events.Event{Type: events.WentLive, Channel: ch, Status: live}); err != nil {
+		t.Fatalf("Notify(went_live) error = %v", err)
+	}
+	if err := telegram.Notify(ctx, events.Event{Type: events.WentOffline, Channel: ch, Previous: live}); err != nil {
+		t.Fatalf("Notify(went_offline) error = %v", err)
+	}
+	// 没有对应开播消息的下播事件直接忽略
+	if err := telegram.Notify(ctx, events.Event{Type: events.WentOffline, Channel: ch, Previous: live}); err != nil {
+		t.Fatalf("Notify(went_offline) without message error = %v", err)
+	}
+
+	if strings.Join(stub.calls, ",") != "sendPhoto,sendMessage,deleteMessage" {
+		t.Fatalf("calls = %v", stub.calls)
+	}
+	if stub.params[2]["message_id"] != float64(42) {
+		t.Errorf("deleteMessage params = %v", stub.params[2])
+	}
+}
+
+func TestNewTelegramValidation(t *testing.T) {
+	tests := []models.TelegramConfig{
+		{ChatID: "1"},
+		{BotToken: "token"},
+		{BotToken: "token", ChatID: "1", OnOffline: "archive"},
+		{BotToken: "token", ChatID: "1", APIBaseURL: "localhost:8081"},
+	}
+	for _, cfg := range tests {
+		if _, err := NewTelegram(cfg); err == nil {
+			t.Errorf("NewTelegram(%+v) should return error", cfg)
+		}
+	}
+
+	telegram, err := NewTelegram(models.TelegramConfig{BotToken: "secret", ChatID: "@channel"})
+	if err != nil || telegram.baseURL != TelegramAPIBaseURL || strings.Contains(telegram.Name(), "secret") {
+		t.Errorf("NewTelegram() = %+v, %v", telegram, err)
+	}
+}
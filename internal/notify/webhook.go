@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"live-channels/internal/events"
+	"live-channels/internal/logger"
+	"live-channels/internal/models"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// Webhook 默认参数
+const (
+	defaultWebhookTimeout    = 10 * time.Second
+	defaultWebhookRetryCount = 3
+	webhookRetryWaitTime     = time.Second      // 首次重试等待时间，之后指数增长
+	webhookRetryMaxWaitTime  = 30 * time.Second // 重试等待时间上限
+)
+
+// templateFuncs Webhook 模板可用的函数
+var templateFuncs = template.FuncMap{
+	// json 将值编码为 JSON，便于在 JSON 模板中安全嵌入字符串
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Webhook 通过 HTTP 请求发送事件通知
+type Webhook struct {
+	name   string
+	cfg    models.WebhookConfig
+	method string
+	body   *template.Template // 为空时发送事件 JSON
+	client *resty.Client
+}
+
+// NewWebhook 根据配置创建 Webhook，地址或模板无效时返回错误
+func NewWebhook(cfg models.WebhookConfig) (*Webhook, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook url %q", cfg.URL)
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = u.Host
+	}
+	method := strings.ToUpper(cfg.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	var body *template.Template
+	if cfg.Body != "" {
+		body, err = template.New(name).Funcs(templateFuncs).Parse(cfg.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook %s body template: %w", name, err)
+		}
+	}
+
+	timeout := defaultWebhookTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	retryCount := defaultWebhookRetryCount
+	if cfg.RetryCount != nil {
+		retryCount = *cfg.RetryCount
+	}
+
+	client := resty.New().
+		SetTimeout(timeout).
+		SetRetryCount(retryCount).
+		SetRetryWaitTime(webhookRetryWaitTime).
+		SetRetryMaxWaitTime(webhookRetryMaxWaitTime).
+		AddRetryCondition(func(r *resty.Response, err error) bool {
+			return err != nil || r.StatusCode() >= http.StatusInternalServerError || r.StatusCode() == http.StatusTooManyRequests
+		}).
+		AddRetryHook(func(r *resty.Response, err error) {
+			fields := []zap.Field{zap.String("webhook", name), zap.Error(err)}
+			if r != nil && r.Request != nil {
+				fields = append(fields, zap.Int("attempt", r.Request.Attempt), zap.Int("status", r.StatusCode()))
+			}
+			logger.Warn("Webhook delivery failed, retrying", fields...)
+		})
+
+	return &Webhook{
+		name:   name,
+		cfg:    cfg,
+		method: method,
+		body:   body,
+		client: client,
+	}, nil
+}
+
+// Name 返回 Webhook 名称
+func (w *Webhook) Name() string {
+	return w.name
+}
+
+// Notify 渲染模板并发送请求，网络错误、5xx 和 429 响应按指数退避重试
+func (w *Webhook) Notify(ctx context.Context, e events.Event) error {
+	if !matchFilter(w.cfg.Filter, e) {
+		return nil
+	}
+
+	body, err := w.render(e)
+	if err != nil {
+		return err
+	}
+
+	req := w.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeaders(w.cfg.Headers).
+		SetBody(body)
+	resp, err := req.Execute(w.method, w.cfg.URL)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", w.name, err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("webhook %s: unexpected status %s after %d attempts", w.name, resp.Status(), resp.Request.Attempt)
+	}
+
+	logger.Info("Webhook delivered",
+		zap.String("webhook", w.name),
+		zap.String("type", string(e.Type)),
+		zap.String("channel_id", e.Channel.Key()),
+		zap.Int("status", resp.StatusCode()),
+		zap.Int("attempts", resp.Request.Attempt),
+	)
+	return nil
+}
+
+// render 生成请求体
+func (w *Webhook) render(e events.Event) ([]byte, error) {
+	if w.body == nil {
+		return json.Marshal(e)
+	}
+
+	var buf strings.Builder
+	if err := w.body.Execute(&buf, e); err != nil {
+		return nil, fmt.Errorf("failed to render webhook %s body: %w", w.name, err)
+	}
+	return []byte(buf.String()), nil
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"live-channels/internal/events"
+	"live-channels/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次返回 503，验证重试
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Method/Authorization = %s/%q", r.Method, r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]string
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("invalid json body %q: %v", body, err)
+		}
+		if payload["text"] != `主播 "小明" went_live: 标题` {
+			t.Errorf("text = %q", payload["text"])
+		}
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(models.WebhookConfig{
+		URL:     server.URL,
+		Method:  "put",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Body:    `{"text": {{ json (printf "%s %s: %s" .Status.Name .Type .Status.Title) }}}`,
+		Filter:  models.EventFilter{Events: []string{"went_live"}},
+	})
+	if err != nil {
+		t.Fatalf("NewWebhook() error = %v", err)
+	}
+	webhook.client.SetRetryWaitTime(time.Millisecond).SetRetryMaxWaitTime(time.Millisecond)
+
+	e := events.Event{
+		Type:   events.WentLive,
+		Status: models.StreamStatus{Name: `主播 "小明"`, Title: "标题"},
+	}
+	if err := webhook.Notify(context.Background(), e); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+
+	// 不匹配过滤条件的事件不发送
+	if err := webhook.Notify(context.Background(), events.Event{Type: events.WentOffline}); err != nil {
+		t.Errorf("Notify() for filtered event error = %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("filtered event should not be sent, attempts = %d", got)
+	}
+}
+
+func TestWebhookDefaultBodyAndFailure(t *testing.T) {
+	var received events.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Method = %s, want POST", r.Method)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	retryCount := 0
+	webhook, err := NewWebhook(models.WebhookConfig{URL: server.URL, RetryCount: &retryCount})
+	if err != nil {
+		t.Fatalf("NewWebhook() error = %v", err)
+	}
+
+	e := events.Event{Type: events.TitleChanged, Status: models.StreamStatus{Title: "新标题"}}
+	if err := webhook.Notify(context.Background(), e); err == nil {
+		t.Errorf("Notify() with 400 response should return error")
+	}
+	if received.Type != events.TitleChanged || received.Status.Title != "新标题" {
+		t.Errorf("default body = %+v", received)
+	}
+}
@@ -0,0 +1,258 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"live-channels/internal/models"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// AcFunLiveBaseURL AcFun 直播默认地址
+	AcFunLiveBaseURL = "https://live.acfun.cn"
+	// AcFunIDBaseURL AcFun 账号接口默认地址
+	AcFunIDBaseURL = "https://id.app.acfun.cn"
+
+	// AcFunEndpointID 账号接口的 endpoints 名称，base_url 对应直播接口地址
+	AcFunEndpointID = "id"
+)
+
+// 直播信息接口的 result 错误码
+const (
+	acfunResultVisitorInvalid = -401   // 游客凭据无效或已过期
+	acfunResultUserNotFound   = 380023 // 主播不存在
+)
+
+// errAcFunVisitorInvalid 游客凭据失效，只有这种情况才需要重新登录
+var errAcFunVisitorInvalid = fmt.Errorf("acfun visitor token invalid: %w", ErrUpstream)
+
+// AcFunVisitorResponse 游客登录响应
+type AcFunVisitorResponse struct {
+	Result    int    `json:"result"`
+	UserID    int64  `json:"userId"`
+	VisitorST string `json:"acfun.api.visitor_st"`
+}
+
+// AcFunLiveInfoResponse 直播信息响应，未开播时 liveId 为空
+type AcFunLiveInfoResponse struct {
+	Result      int      `json:"result"`
+	ErrorMsg    string   `json:"error_msg"`
+	LiveID      string   `json:"liveId"`
+	Title       string   `json:"title"`
+	OnlineCount int      `json:"onlineCount"` // 在线观众数
+	CoverURLs   []string `json:"coverUrls"`
+	CreateTime  int64    `json:"createTime"` // 开播时间（毫秒时间戳）
+	Type        struct {
+		CategoryName string `json:"categoryName"` // 一级分类
+		Name         string `json:"name"`         // 二级分类
+	} `json:"type"`
+	User struct {
+		Name    string `json:"name"`
+		HeadURL string `json:"headUrl"`
+	} `json:"user"`
+}
+
+// acfunVisitor 游客登录凭据
+type acfunVisitor struct {
+	did       string
+	userID    int64
+	visitorST string
+}
+
+func init() {
+	Register(Descriptor{
+		Name:        models.PlatformAcFun,
+		DisplayName: "AcFun",
+		Icon:        "https://live.acfun.cn/favicon.ico",
+		URLPatterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?live\.acfun\.cn/live/(\d+)(?:[/?#]|$)`),
+		},
+		Endpoints: []string{AcFunEndpointID},
+		New:       func(cfg models.PlatformConfig) StreamProvider { return NewAcFunClientWithConfig(cfg) },
+	})
+}
+
+// AcFunClient AcFun 平台客户端
+type AcFunClient struct {
+	client      *resty.Client
+	liveBaseURL string
+	idBaseURL   string
+
+	mu      sync.Mutex    // 保护 visitor
+	visitor *acfunVisitor // 游客凭据，首次请求时登录获取
+}
+
+// NewAcFunClient 创建 AcFun 客户端
+func NewAcFunClient() *AcFunClient {
+	return NewAcFunClientWithBaseURL(AcFunLiveBaseURL, AcFunIDBaseURL)
+}
+
+// NewAcFunClientWithBaseURL 使用指定地址创建 AcFun 客户端（便于测试）
+func NewAcFunClientWithBaseURL(liveBaseURL, idBaseURL string) *AcFunClient {
+	return &AcFunClient{
+		client:      GetHTTPClient(),
+		liveBaseURL: liveBaseURL,
+		idBaseURL:   idBaseURL,
+	}
+}
+
+// NewAcFunClientWithConfig 使用平台配置创建 AcFun 客户端
+func NewAcFunClientWithConfig(cfg models.PlatformConfig) *AcFunClient {
+	c := NewAcFunClientWithBaseURL(baseURLOr(cfg, AcFunLiveBaseURL), endpointOr(cfg, AcFunEndpointID, AcFunIDBaseURL))
+	c.client = newHTTPClient(cfg)
+	return c
+}
+
+// GetStreamStatus 获取 AcFun 直播状态
+// channelID 为主播 UID，即 live.acfun.cn/live/{id}
+// API: https://live.acfun.cn/api/live/info?authorId={id}
+func (a *AcFunClient) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	info, err := a.getLiveInfo(ctx, channelID)
+	if errors.Is(err, errAcFunVisitorInvalid) {
+		// 游客凭据已失效，重新登录后再试一次；其他错误不重试
+		a.resetVisitor()
+		info, err = a.getLiveInfo(ctx, channelID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := info.User.Name
+	if name == "" {
+		name = channelID
+	}
+
+	status := &models.StreamStatus{
+		ChannelID:    channelID,
+		Name:         name,
+		Platform:     "acfun",
+		Title:        info.Title,
+		Viewers:      info.OnlineCount,
+		ThumbnailURL: firstURL(info.CoverURLs),
+		AvatarURL:    info.User.HeadURL,
+		ProfileURL:   fmt.Sprintf("https://live.acfun.cn/live/%s", channelID),
+		UpdatedAt:    time.Now().Unix(),
+	}
+	status.SetState(models.StateFromLive(info.LiveID != ""))
+	setCategory(status, info.Type.Name, info.Type.CategoryName, "")
+	if status.IsLive && info.CreateTime > 0 {
+		status.LiveSince = info.CreateTime / 1000
+	}
+
+	return status, nil
+}
+
+// getLiveInfo 使用游客凭据请求直播信息接口
+func (a *AcFunClient) getLiveInfo(ctx context.Context, channelID string) (*AcFunLiveInfoResponse, error) {
+	visitor, err := a.getVisitor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"authorId":             channelID,
+			"userId":               fmt.Sprintf("%d", visitor.userID),
+			"acfun.api.visitor_st": visitor.visitorST,
+		}).
+		SetCookie(&http.Cookie{Name: "_did", Value: visitor.did}).
+		SetHeader("Referer", a.liveBaseURL+"/").
+		Get(a.liveBaseURL + "/api/live/info")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch acfun live info: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch acfun live info: %w", err)
+	}
+
+	var info AcFunLiveInfoResponse
+	if err := json.Unmarshal(resp.Body(), &info); err != nil {
+		return nil, fmt.Errorf("failed to parse acfun response: %w: %w", ErrParse, err)
+	}
+
+	switch info.Result {
+	case 0:
+	case acfunResultVisitorInvalid:
+		return nil, errAcFunVisitorInvalid
+	case acfunResultUserNotFound:
+		return nil, fmt.Errorf("acfun author %s: %w", channelID, ErrChannelNotFound)
+	default:
+		return nil, fmt.Errorf("acfun api error: result %d %s: %w", info.Result, info.ErrorMsg, ErrUpstream)
+	}
+
+	return &info, nil
+}
+
+// getVisitor 获取游客凭据，首次调用时完成游客登录握手并缓存
+// 1. 访问直播首页获取 _did Cookie
+// 2. API: https://id.app.acfun.cn/rest/app/visitor/login 获取 userId 和 visitor_st
+func (a *AcFunClient) getVisitor(ctx context.Context) (*acfunVisitor, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.visitor != nil {
+		return a.visitor, nil
+	}
+
+	pageResp, err := a.client.R().
+		SetContext(ctx).
+		Get(a.liveBaseURL + "/")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch acfun did: %w: %w", ErrUpstream, err)
+	}
+
+	var did string
+	for _, cookie := range pageResp.Cookies() {
+		if cookie.Name == "_did" {
+			did = cookie.Value
+			break
+		}
+	}
+	if did == "" {
+		return nil, fmt.Errorf("acfun _did cookie not found: %w", ErrUpstream)
+	}
+
+	resp, err := a.client.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{"sid": "acfun.api.visitor"}).
+		SetCookie(&http.Cookie{Name: "_did", Value: did}).
+		Post(a.idBaseURL + "/rest/app/visitor/login")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to login acfun visitor: %w: %w", ErrUpstream, err)
+	}
+
+	var visitorResp AcFunVisitorResponse
+	if err := json.Unmarshal(resp.Body(), &visitorResp); err != nil {
+		return nil, fmt.Errorf("failed to parse acfun visitor response: %w: %w", ErrParse, err)
+	}
+
+	if visitorResp.Result != 0 || visitorResp.VisitorST == "" {
+		return nil, fmt.Errorf("acfun visitor login error: result %d: %w", visitorResp.Result, ErrUpstream)
+	}
+
+	a.visitor = &acfunVisitor{
+		did:       did,
+		userID:    visitorResp.UserID,
+		visitorST: visitorResp.VisitorST,
+	}
+
+	return a.visitor, nil
+}
+
+// resetVisitor 清除缓存的游客凭据
+func (a *AcFunClient) resetVisitor() {
+	a.mu.Lock()
+	a.visitor = nil
+	a.mu.Unlock()
+}
@@ -0,0 +1,117 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAcFunGetStreamStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "_did", Value: "web_test_did"})
+	})
+	mux.HandleFunc("/rest/app/visitor/login", func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("_did"); err != nil || cookie.Value != "web_test_did" || r.FormValue("sid") != "acfun.api.visitor" {
+			w.Write([]byte(`{"result":-1}`))
+			return
+		}
+		w.Write([]byte(`{"result":0,"userId":1000,"acfun.api.visitor_st":"test-st"}`))
+	})
+	mux.HandleFunc("/api/live/info", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("acfun.api.visitor_st") != "test-st" {
+			w.Write([]byte(`{"result":-401}`))
+			return
+		}
+		if r.URL.Query().Get("authorId") == "offline" {
+			w.Write([]byte(`{"result":0,"liveId":"","user":{"name":"离线主播"}}`))
+			return
+		}
+		w.Write([]byte(`{"result":0,"liveId":"live123","title":"测试直播","onlineCount":888,"coverUrls":["https://example.com/cover.jpg"],"user":{"name":"测试主播","headUrl":"https://example.com/head.jpg"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewAcFunClientWithBaseURL(server.URL, server.URL)
+	status, err := client.GetStreamStatus(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+	if !status.IsLive || status.Viewers != 888 || status.Name != "测试主播" || status.Title != "测试直播" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if status.ThumbnailURL != "https://example.com/cover.jpg" || status.AvatarURL != "https://example.com/head.jpg" {
+		t.Errorf("ThumbnailURL/AvatarURL = %q/%q", status.ThumbnailURL, status.AvatarURL)
+	}
+
+	offline, err := client.GetStreamStatus(context.Background(), "offline")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() offline error = %v", err)
+	}
+	if offline.IsLive || offline.Name != "离线主播" {
+		t.Errorf("unexpected offline status: %+v", offline)
+	}
+}
+
+func TestAcFunRelogin(t *testing.T) {
+	var logins, infos atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "_did", Value: "web_test_did"})
+	})
+	mux.HandleFunc("/rest/app/visitor/login", func(w http.ResponseWriter, r *http.Request) {
+		n := logins.Add(1)
+		w.Write([]byte(fmt.Sprintf(`{"result":0,"userId":1000,"acfun.api.visitor_st":"st-%d"}`, n)))
+	})
+	mux.HandleFunc("/api/live/info", func(w http.ResponseWriter, r *http.Request) {
+		infos.Add(1)
+		switch {
+		case r.URL.Query().Get("authorId") == "404":
+			w.Write([]byte(`{"result":380023,"error_msg":"用户不存在"}`))
+		case r.URL.Query().Get("authorId") == "500":
+			w.Write([]byte(`{"result":-1,"error_msg":"服务繁忙"}`))
+		case r.URL.Query().Get("acfun.api.visitor_st") != "st-2":
+			// 第一次登录获取的凭据视为已过期
+			w.Write([]byte(`{"result":-401}`))
+		default:
+			w.Write([]byte(`{"result":0,"liveId":"","user":{"name":"主播"}}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// 游客凭据失效时重新登录并重试一次
+	client := NewAcFunClientWithBaseURL(server.URL, server.URL)
+	if _, err := client.GetStreamStatus(context.Background(), "12345"); err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+	if logins.Load() != 2 || infos.Load() != 2 {
+		t.Errorf("logins/infos = %d/%d, want 2/2", logins.Load(), infos.Load())
+	}
+
+	// 主播不存在映射为 ErrChannelNotFound，其他错误不重新登录
+	logins.Store(0)
+	infos.Store(0)
+	if _, err := client.GetStreamStatus(context.Background(), "404"); !errors.Is(err, ErrChannelNotFound) {
+		t.Errorf("GetStreamStatus() missing author error = %v, want ErrChannelNotFound", err)
+	}
+	if _, err := client.GetStreamStatus(context.Background(), "500"); !errors.Is(err, ErrUpstream) {
+		t.Errorf("GetStreamStatus() api error = %v, want ErrUpstream", err)
+	}
+	if logins.Load() != 0 || infos.Load() != 2 {
+		t.Errorf("logins/infos = %d/%d, want 0/2", logins.Load(), infos.Load())
+	}
+
+	// 游客凭据保存在客户端实例上，新客户端需要自行登录
+	other := NewAcFunClientWithBaseURL(server.URL, server.URL)
+	if _, err := other.getVisitor(context.Background()); err != nil {
+		t.Fatalf("getVisitor() error = %v", err)
+	}
+	if logins.Load() != 1 || other.visitor.visitorST == client.visitor.visitorST {
+		t.Errorf("new client visitor_st = %q, logins = %d, want own login", other.visitor.visitorST, logins.Load())
+	}
+}
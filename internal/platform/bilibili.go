@@ -1,25 +1,37 @@
 package platform
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"live-channels/internal/models"
+	"regexp"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 )
 
+// BilibiliAPIBaseURL B 站直播接口默认地址
+const BilibiliAPIBaseURL = "https://api.live.bilibili.com"
+
 // BilibiliResponse 直播间信息响应
 type BilibiliResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Data    struct {
-		LiveStatus  int    `json:"live_status"`
-		Title       string `json:"title"`
-		RoomID      int    `json:"room_id"`
-		OnlineCount int    `json:"online"`
-		KeyFrame    string `json:"keyframe"`
-		UserInfo    struct {
+		LiveStatus     int    `json:"live_status"` // 0 为未开播，1 为直播中，2 为轮播中
+		Title          string `json:"title"`
+		RoomID         int    `json:"room_id"`
+		OnlineCount    int    `json:"online"`
+		KeyFrame       string `json:"keyframe"`
+		LiveTime       string `json:"live_time"` // 开播时间，格式 2006-01-02 15:04:05（北京时间）
+		AreaID         int    `json:"area_id"`
+		AreaName       string `json:"area_name"`
+		ParentAreaID   int    `json:"parent_area_id"`
+		ParentAreaName string `json:"parent_area_name"`
+		UserInfo       struct {
 			Info struct {
 				Uname string `json:"uname"`
 			} `json:"info"`
@@ -39,51 +51,146 @@ type BilibiliAnchorResponse struct {
 	} `json:"data"`
 }
 
+// BilibiliRoomInfoOldResponse 通过 UID 查询直播间响应
+type BilibiliRoomInfoOldResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		RoomStatus int `json:"roomStatus"` // 是否开通直播间：0 为未开通，1 为已开通
+		RoomID     int `json:"roomid"`
+	} `json:"data"`
+}
+
+// BilibiliRoomInitResponse 直播间初始化信息响应，用于获取房间对应的主播 UID
+type BilibiliRoomInitResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		RoomID int   `json:"room_id"`
+		UID    int64 `json:"uid"`
+	} `json:"data"`
+}
+
+// BilibiliStatusInfo 批量接口中单个主播的直播间信息
+type BilibiliStatusInfo struct {
+	Title          string `json:"title"`
+	RoomID         int    `json:"room_id"`
+	UID            int64  `json:"uid"`
+	Online         int    `json:"online"`
+	LiveStatus     int    `json:"live_status"`
+	Uname          string `json:"uname"`
+	Face           string `json:"face"`
+	KeyFrame       string `json:"keyframe"`
+	LiveTime       int64  `json:"live_time"` // 开播时间（Unix 时间戳）
+	AreaID         int    `json:"area_v2_id"`
+	AreaName       string `json:"area_v2_name"`
+	ParentAreaID   int    `json:"area_v2_parent_id"`
+	ParentAreaName string `json:"area_v2_parent_name"`
+}
+
+// BilibiliStatusInfoResponse 批量查询直播间状态响应，data 以 UID 为键
+type BilibiliStatusInfoResponse struct {
+	Code    int                           `json:"code"`
+	Message string                        `json:"message"`
+	Data    map[string]BilibiliStatusInfo `json:"data"`
+}
+
 // AnchorInfo 主播信息
 type AnchorInfo struct {
 	Uname string // 主播名字
 	Face  string // 主播头像 URL
 }
 
+// bilibiliUIDRooms UID 到直播间号的映射缓存，直播间号不会变化，所有客户端实例共享
+var (
+	bilibiliUIDRooms   = make(map[string]string)
+	bilibiliUIDRoomsMu sync.RWMutex
+)
+
+// bilibiliRoomUIDs 直播间号到主播 UID 的映射缓存，供批量查询使用
+var (
+	bilibiliRoomUIDs   = make(map[string]string)
+	bilibiliRoomUIDsMu sync.RWMutex
+)
+
+// bilibiliBatchSize 批量接口单次查询的最大 UID 数量
+const bilibiliBatchSize = 100
+
+func init() {
+	Register(Descriptor{
+		Name:        models.PlatformBilibili,
+		DisplayName: "B站",
+		Icon:        "https://www.bilibili.com/favicon.ico",
+		URLPatterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?live\.bilibili\.com/(?:h5/)?(\d+)(?:[/?#]|$)`),
+		},
+		ShortLinks: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?b23\.tv/\w+`),
+		},
+		Capabilities: Capabilities{UID: true, Batch: true},
+		New:          func(cfg models.PlatformConfig) StreamProvider { return NewBilibiliClientWithConfig(cfg) },
+	})
+}
+
 // BilibiliClient Bilibili 平台客户端
 type BilibiliClient struct {
-	client *resty.Client
+	client  *resty.Client
+	baseURL string
 }
 
 // NewBilibiliClient 创建 Bilibili 客户端
 func NewBilibiliClient() *BilibiliClient {
+	return NewBilibiliClientWithConfig(models.PlatformConfig{})
+}
+
+// NewBilibiliClientWithBaseURL 使用指定地址创建 Bilibili 客户端（便于测试）
+func NewBilibiliClientWithBaseURL(baseURL string) *BilibiliClient {
+	return NewBilibiliClientWithConfig(models.PlatformConfig{BaseURL: baseURL})
+}
+
+// NewBilibiliClientWithConfig 使用平台配置创建 Bilibili 客户端
+func NewBilibiliClientWithConfig(cfg models.PlatformConfig) *BilibiliClient {
 	return &BilibiliClient{
-		client: GetHTTPClient(),
+		client:  newHTTPClient(cfg),
+		baseURL: baseURLOr(cfg, BilibiliAPIBaseURL),
 	}
 }
 
 // GetStreamStatus 获取 B 站直播状态
 // API: https://api.live.bilibili.com/room/v1/Room/get_info?room_id={roomId}
-func (b *BilibiliClient) GetStreamStatus(channelID string) (*models.StreamStatus, error) {
-	url := fmt.Sprintf("https://api.live.bilibili.com/room/v1/Room/get_info?room_id=%s", channelID)
+func (b *BilibiliClient) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	url := fmt.Sprintf("%s/room/v1/Room/get_info?room_id=%s", b.baseURL, channelID)
 
 	// 获取直播间基本信息
 	resp, err := b.client.R().
+		SetContext(ctx).
 		Get(url)
 
 	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bilibili room info: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
 		return nil, fmt.Errorf("failed to fetch bilibili room info: %w", err)
 	}
 
 	var biliResp BilibiliResponse
 	if err := json.Unmarshal(resp.Body(), &biliResp); err != nil {
-		return nil, fmt.Errorf("failed to parse bilibili response: %w", err)
+		return nil, fmt.Errorf("failed to parse bilibili response: %w: %w", ErrParse, err)
 	}
 
 	if biliResp.Code != 0 {
-		return nil, fmt.Errorf("bilibili api error: %s", biliResp.Message)
+		// 房间不存在时多半是把 UID 当作房间号填写了
+		if biliResp.Code == 1 || biliResp.Code == 60004 {
+			return nil, fmt.Errorf("bilibili room %s (if this is a space.bilibili.com UID, configure it as \"uid\"): %s: %w", channelID, biliResp.Message, ErrChannelNotFound)
+		}
+		return nil, bilibiliAPIError(biliResp.Code, biliResp.Message)
 	}
 
-	isLive := biliResp.Data.LiveStatus == 1
+	state := bilibiliLiveState(biliResp.Data.LiveStatus)
 	roomID := biliResp.Data.RoomID
 
 	// 获取主播详细信息（名字和头像）
-	anchorInfo, err := b.getAnchorInfo(roomID)
+	anchorInfo, err := b.getAnchorInfo(ctx, roomID)
 	if err != nil {
 		// 如果获取主播信息失败，使用channelID作为降级方案
 		anchorInfo = &AnchorInfo{
@@ -96,7 +203,6 @@ func (b *BilibiliClient) GetStreamStatus(channelID string) (*models.StreamStatus
 		ChannelID:    channelID,
 		Name:         anchorInfo.Uname,
 		Platform:     "bilibili",
-		IsLive:       isLive,
 		Title:        biliResp.Data.Title,
 		Viewers:      biliResp.Data.OnlineCount,
 		ThumbnailURL: biliResp.Data.KeyFrame,
@@ -104,29 +210,93 @@ func (b *BilibiliClient) GetStreamStatus(channelID string) (*models.StreamStatus
 		ProfileURL:   fmt.Sprintf("https://live.bilibili.com/%s", channelID),
 		UpdatedAt:    time.Now().Unix(),
 	}
+	status.SetState(state)
+	setCategory(status, biliResp.Data.AreaName, biliResp.Data.ParentAreaName,
+		bilibiliAreaURL(biliResp.Data.ParentAreaID, biliResp.Data.AreaID))
+	if status.IsLive {
+		status.LiveSince = parseBilibiliLiveTime(biliResp.Data.LiveTime)
+	}
 
 	return status, nil
 }
 
+// GetStreamStatusByUID 通过用户 UID 获取 B 站直播状态
+// UID 即 space.bilibili.com/{uid}，先解析为直播间号再查询
+func (b *BilibiliClient) GetStreamStatusByUID(ctx context.Context, uid string) (*models.StreamStatus, error) {
+	roomID, err := b.resolveRoomID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return b.GetStreamStatus(ctx, roomID)
+}
+
+// resolveRoomID 将用户 UID 解析为直播间号，结果会被缓存
+// API: https://api.live.bilibili.com/room/v1/Room/getRoomInfoOld?mid={uid}
+func (b *BilibiliClient) resolveRoomID(ctx context.Context, uid string) (string, error) {
+	bilibiliUIDRoomsMu.RLock()
+	roomID, ok := bilibiliUIDRooms[uid]
+	bilibiliUIDRoomsMu.RUnlock()
+	if ok {
+		return roomID, nil
+	}
+
+	url := fmt.Sprintf("%s/room/v1/Room/getRoomInfoOld?mid=%s", b.baseURL, uid)
+
+	resp, err := b.client.R().
+		SetContext(ctx).
+		Get(url)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch bilibili room by uid: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return "", fmt.Errorf("failed to fetch bilibili room by uid: %w", err)
+	}
+
+	var roomResp BilibiliRoomInfoOldResponse
+	if err := json.Unmarshal(resp.Body(), &roomResp); err != nil {
+		return "", fmt.Errorf("failed to parse bilibili room by uid response: %w: %w", ErrParse, err)
+	}
+
+	if roomResp.Code != 0 {
+		return "", bilibiliAPIError(roomResp.Code, roomResp.Message)
+	}
+
+	if roomResp.Data.RoomStatus == 0 || roomResp.Data.RoomID == 0 {
+		return "", fmt.Errorf("bilibili user %s has no live room: %w", uid, ErrChannelNotFound)
+	}
+
+	roomID = strconv.Itoa(roomResp.Data.RoomID)
+	bilibiliUIDRoomsMu.Lock()
+	bilibiliUIDRooms[uid] = roomID
+	bilibiliUIDRoomsMu.Unlock()
+
+	return roomID, nil
+}
+
 // getAnchorInfo 获取主播详细信息
 // API: https://api.live.bilibili.com/live_user/v1/UserInfo/get_anchor_in_room
-func (b *BilibiliClient) getAnchorInfo(roomID int) (*AnchorInfo, error) {
-	url := fmt.Sprintf("https://api.live.bilibili.com/live_user/v1/UserInfo/get_anchor_in_room?roomid=%d", roomID)
+func (b *BilibiliClient) getAnchorInfo(ctx context.Context, roomID int) (*AnchorInfo, error) {
+	url := fmt.Sprintf("%s/live_user/v1/UserInfo/get_anchor_in_room?roomid=%d", b.baseURL, roomID)
 
 	resp, err := b.client.R().
+		SetContext(ctx).
 		Get(url)
 
 	if err != nil {
+		return nil, fmt.Errorf("failed to fetch anchor info: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
 		return nil, fmt.Errorf("failed to fetch anchor info: %w", err)
 	}
 
 	var anchorResp BilibiliAnchorResponse
 	if err := json.Unmarshal(resp.Body(), &anchorResp); err != nil {
-		return nil, fmt.Errorf("failed to parse anchor response: %w", err)
+		return nil, fmt.Errorf("failed to parse anchor response: %w: %w", ErrParse, err)
 	}
 
 	if anchorResp.Code != 0 {
-		return nil, fmt.Errorf("bilibili anchor api error: %s", anchorResp.Message)
+		return nil, bilibiliAPIError(anchorResp.Code, anchorResp.Message)
 	}
 
 	return &AnchorInfo{
@@ -134,3 +304,189 @@ func (b *BilibiliClient) getAnchorInfo(roomID int) (*AnchorInfo, error) {
 		Face:  anchorResp.Data.Info.Face,
 	}, nil
 }
+
+// GetStreamStatuses 批量获取 B 站直播状态
+// 按房间号配置的频道先解析为主播 UID（结果会被缓存），然后一次请求查询所有 UID
+// API: https://api.live.bilibili.com/room/v1/Room/get_status_info_by_uids
+func (b *BilibiliClient) GetStreamStatuses(ctx context.Context, channels []models.ChannelConfig) (map[string]*models.StreamStatus, error) {
+	// UID -> 使用该 UID 的频道列表
+	uidChannels := make(map[string][]models.ChannelConfig)
+	var uids []int64
+	for _, ch := range channels {
+		uid := ch.UID
+		if ch.ChannelID != "" {
+			var err error
+			uid, err = b.resolveUID(ctx, ch.ChannelID)
+			if err != nil {
+				// 解析失败的频道不包含在结果中，由调用方单独查询
+				continue
+			}
+		}
+
+		uidNum, err := strconv.ParseInt(uid, 10, 64)
+		if err != nil {
+			continue
+		}
+		if _, ok := uidChannels[uid]; !ok {
+			uids = append(uids, uidNum)
+		}
+		uidChannels[uid] = append(uidChannels[uid], ch)
+	}
+
+	results := make(map[string]*models.StreamStatus)
+	for start := 0; start < len(uids); start += bilibiliBatchSize {
+		end := start + bilibiliBatchSize
+		if end > len(uids) {
+			end = len(uids)
+		}
+
+		infos, err := b.getStatusInfoByUIDs(ctx, uids[start:end])
+		if err != nil {
+			return results, err
+		}
+
+		for uid, info := range infos {
+			// 批量结果包含直播间号，缓存 UID 到直播间号的映射，之后单独查询该 UID 时无需再解析
+			if info.RoomID != 0 {
+				bilibiliUIDRoomsMu.Lock()
+				bilibiliUIDRooms[uid] = strconv.Itoa(info.RoomID)
+				bilibiliUIDRoomsMu.Unlock()
+			}
+			for _, ch := range uidChannels[uid] {
+				channelID := ch.ChannelID
+				if channelID == "" {
+					channelID = strconv.Itoa(info.RoomID)
+				}
+				status := &models.StreamStatus{
+					ChannelID:    channelID,
+					Name:         info.Uname,
+					Platform:     "bilibili",
+					Title:        info.Title,
+					Viewers:      info.Online,
+					ThumbnailURL: info.KeyFrame,
+					AvatarURL:    info.Face,
+					ProfileURL:   fmt.Sprintf("https://live.bilibili.com/%s", channelID),
+					UpdatedAt:    time.Now().Unix(),
+				}
+				status.SetState(bilibiliLiveState(info.LiveStatus))
+				setCategory(status, info.AreaName, info.ParentAreaName,
+					bilibiliAreaURL(info.ParentAreaID, info.AreaID))
+				if status.IsLive {
+					status.LiveSince = info.LiveTime
+				}
+				results[ch.Key()] = status
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// getStatusInfoByUIDs 一次请求查询多个主播的直播间信息
+func (b *BilibiliClient) getStatusInfoByUIDs(ctx context.Context, uids []int64) (map[string]BilibiliStatusInfo, error) {
+	resp, err := b.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string][]int64{"uids": uids}).
+		Post(b.baseURL + "/room/v1/Room/get_status_info_by_uids")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bilibili status info: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch bilibili status info: %w", err)
+	}
+
+	var statusResp BilibiliStatusInfoResponse
+	if err := json.Unmarshal(resp.Body(), &statusResp); err != nil {
+		return nil, fmt.Errorf("failed to parse bilibili status info response: %w: %w", ErrParse, err)
+	}
+
+	if statusResp.Code != 0 {
+		return nil, bilibiliAPIError(statusResp.Code, statusResp.Message)
+	}
+
+	return statusResp.Data, nil
+}
+
+// resolveUID 将直播间号解析为主播 UID，结果会被缓存
+// API: https://api.live.bilibili.com/room/v1/Room/room_init?id={roomId}
+func (b *BilibiliClient) resolveUID(ctx context.Context, roomID string) (string, error) {
+	bilibiliRoomUIDsMu.RLock()
+	uid, ok := bilibiliRoomUIDs[roomID]
+	bilibiliRoomUIDsMu.RUnlock()
+	if ok {
+		return uid, nil
+	}
+
+	url := fmt.Sprintf("%s/room/v1/Room/room_init?id=%s", b.baseURL, roomID)
+
+	resp, err := b.client.R().
+		SetContext(ctx).
+		Get(url)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch bilibili room init: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return "", fmt.Errorf("failed to fetch bilibili room init: %w", err)
+	}
+
+	var initResp BilibiliRoomInitResponse
+	if err := json.Unmarshal(resp.Body(), &initResp); err != nil {
+		return "", fmt.Errorf("failed to parse bilibili room init response: %w: %w", ErrParse, err)
+	}
+
+	if initResp.Code != 0 || initResp.Data.UID == 0 {
+		return "", bilibiliAPIError(initResp.Code, initResp.Message)
+	}
+
+	uid = strconv.FormatInt(initResp.Data.UID, 10)
+	bilibiliRoomUIDsMu.Lock()
+	bilibiliRoomUIDs[roomID] = uid
+	bilibiliRoomUIDsMu.Unlock()
+
+	return uid, nil
+}
+
+// bilibiliLiveState 转换直播状态：0 为未开播，1 为直播中，2 为轮播中
+func bilibiliLiveState(liveStatus int) models.StreamState {
+	switch liveStatus {
+	case 1:
+		return models.StateLive
+	case 2:
+		return models.StateReplay
+	default:
+		return models.StateOffline
+	}
+}
+
+// bilibiliAreaURL 生成分区页面链接
+func bilibiliAreaURL(parentAreaID, areaID int) string {
+	if parentAreaID == 0 || areaID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("https://live.bilibili.com/p/eden/area-tags?parentAreaId=%d&areaId=%d", parentAreaID, areaID)
+}
+
+// bilibiliLocation B 站接口返回的时间均为北京时间
+var bilibiliLocation = time.FixedZone("CST", 8*3600)
+
+// parseBilibiliLiveTime 解析开播时间字符串，未开播时接口返回 "0000-00-00 00:00:00"
+func parseBilibiliLiveTime(liveTime string) int64 {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", liveTime, bilibiliLocation)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// bilibiliAPIError 将 B 站接口错误码转换为对应分类的错误
+// -412 为请求被拦截，-352 为风控校验失败，均表示当前 IP 或请求特征被限制
+func bilibiliAPIError(code int, message string) error {
+	switch code {
+	case -412, -352:
+		return fmt.Errorf("bilibili api error %d: %s: %w", code, message, ErrBlocked)
+	}
+	return fmt.Errorf("bilibili api error %d: %s: %w", code, message, ErrUpstream)
+}
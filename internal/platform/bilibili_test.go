@@ -0,0 +1,164 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"live-channels/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+)
+
+// resetBilibiliCaches 清空房间号与 UID 的映射缓存，避免测试之间互相影响
+func resetBilibiliCaches(t *testing.T) {
+	reset := func() {
+		bilibiliUIDRoomsMu.Lock()
+		bilibiliUIDRooms = make(map[string]string)
+		bilibiliUIDRoomsMu.Unlock()
+		bilibiliRoomUIDsMu.Lock()
+		bilibiliRoomUIDs = make(map[string]string)
+		bilibiliRoomUIDsMu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// newBilibiliBatchServer 模拟 B 站接口：房间 100、200 分别属于 UID 1001、2002，房间 300 不存在，
+// 批量接口只返回 UID 1001 和 3003（UID 2002 缺失）
+func newBilibiliBatchServer(t *testing.T, roomInits, batches, singles *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/room/v1/Room/room_init":
+			roomInits.Add(1)
+			switch r.URL.Query().Get("id") {
+			case "100":
+				w.Write([]byte(`{"code":0,"message":"ok","data":{"room_id":100,"uid":1001}}`))
+			case "200":
+				w.Write([]byte(`{"code":0,"message":"ok","data":{"room_id":200,"uid":2002}}`))
+			default:
+				w.Write([]byte(`{"code":60004,"message":"直播间不存在","data":{}}`))
+			}
+		case "/room/v1/Room/get_status_info_by_uids":
+			batches.Add(1)
+			if r.Method != http.MethodPost {
+				t.Errorf("batch method = %s, want POST", r.Method)
+			}
+			var body struct {
+				UIDs []int64 `json:"uids"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode batch body: %v", err)
+			}
+			sort.Slice(body.UIDs, func(i, j int) bool { return body.UIDs[i] < body.UIDs[j] })
+			if len(body.UIDs) != 3 || body.UIDs[0] != 1001 || body.UIDs[1] != 2002 || body.UIDs[2] != 3003 {
+				t.Errorf("batch uids = %v, want [1001 2002 3003]", body.UIDs)
+			}
+			w.Write([]byte(`{"code":0,"message":"success","data":{
+				"1001":{"title":"直播一","room_id":100,"uid":1001,"online":321,"live_status":1,"uname":"主播一","face":"https://example.com/1.jpg","keyframe":"https://example.com/k1.jpg","live_time":1700000000,"area_v2_id":86,"area_v2_name":"英雄联盟","area_v2_parent_id":2,"area_v2_parent_name":"网游"},
+				"3003":{"title":"直播三","room_id":300300,"uid":3003,"online":0,"live_status":2,"uname":"主播三"}
+			}}`))
+		case "/room/v1/Room/get_info":
+			singles.Add(1)
+			w.Write([]byte(`{"code":0,"message":"ok","data":{"live_status":0,"title":"单独查询","room_id":200}}`))
+		case "/live_user/v1/UserInfo/get_anchor_in_room":
+			w.Write([]byte(`{"code":0,"message":"ok","data":{"info":{"uname":"主播二","face":""}}}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestBilibiliGetStreamStatuses(t *testing.T) {
+	resetBilibiliCaches(t)
+	var roomInits, batches, singles atomic.Int32
+	server := newBilibiliBatchServer(t, &roomInits, &batches, &singles)
+	defer server.Close()
+
+	client := NewBilibiliClientWithConfig(models.PlatformConfig{BaseURL: server.URL})
+	channels := []models.ChannelConfig{
+		{Platform: models.PlatformBilibili, ChannelID: "100"},
+		{Platform: models.PlatformBilibili, ChannelID: "200"},
+		{Platform: models.PlatformBilibili, UID: "3003"},
+		{Platform: models.PlatformBilibili, ChannelID: "300"},
+	}
+
+	statuses, err := client.GetStreamStatuses(context.Background(), channels)
+	if err != nil {
+		t.Fatalf("GetStreamStatuses() error = %v", err)
+	}
+	if batches.Load() != 1 || roomInits.Load() != 3 {
+		t.Errorf("batch/room_init calls = %d/%d, want 1/3", batches.Load(), roomInits.Load())
+	}
+
+	// 批量结果以频道 Key 为键：房间号频道用房间号，UID 频道用 "uid:" 前缀
+	live := statuses["100"]
+	if live == nil || !live.IsLive || live.Name != "主播一" || live.Viewers != 321 || live.LiveSince != 1700000000 {
+		t.Fatalf("statuses[100] = %+v", live)
+	}
+	if live.Game != "英雄联盟" || live.Category.URL == "" || live.ProfileURL != "https://live.bilibili.com/100" {
+		t.Errorf("statuses[100] category/profile = %+v/%q", live.Category, live.ProfileURL)
+	}
+	byUID := statuses["uid:3003"]
+	if byUID == nil || byUID.ChannelID != "300300" || byUID.State != models.StateReplay || byUID.LiveSince != 0 {
+		t.Errorf("statuses[uid:3003] = %+v", byUID)
+	}
+
+	// 批量结果中缺失的 UID 和无法解析的房间不包含在结果中，由调用方单独查询
+	if _, ok := statuses["200"]; ok {
+		t.Error("statuses[200] present, want missing for single-request fallback")
+	}
+	if _, ok := statuses["300"]; ok {
+		t.Error("statuses[300] present, want missing for single-request fallback")
+	}
+	if len(statuses) != 2 {
+		t.Errorf("len(statuses) = %d, want 2", len(statuses))
+	}
+
+	// 房间号到 UID 的映射已缓存，再次查询只请求未解析成功的房间
+	if _, err := client.GetStreamStatuses(context.Background(), channels); err != nil {
+		t.Fatalf("second GetStreamStatuses() error = %v", err)
+	}
+	if batches.Load() != 2 || roomInits.Load() != 4 {
+		t.Errorf("after second call batch/room_init calls = %d/%d, want 2/4", batches.Load(), roomInits.Load())
+	}
+
+	// 批量结果同时缓存 UID 到直播间号的映射，按 UID 单独查询时不再请求 getRoomInfoOld
+	status, err := client.GetStreamStatusByUID(context.Background(), "3003")
+	if err != nil {
+		t.Fatalf("GetStreamStatusByUID() error = %v", err)
+	}
+	if status.ChannelID != "300300" || singles.Load() != 1 {
+		t.Errorf("GetStreamStatusByUID() ChannelID = %q, get_info calls = %d", status.ChannelID, singles.Load())
+	}
+}
+
+func TestBilibiliResolveUID(t *testing.T) {
+	resetBilibiliCaches(t)
+	var roomInits, batches, singles atomic.Int32
+	server := newBilibiliBatchServer(t, &roomInits, &batches, &singles)
+	defer server.Close()
+
+	client := NewBilibiliClientWithConfig(models.PlatformConfig{BaseURL: server.URL})
+	for i := 0; i < 2; i++ {
+		uid, err := client.resolveUID(context.Background(), "100")
+		if err != nil || uid != "1001" {
+			t.Fatalf("resolveUID(100) = %q, %v, want 1001", uid, err)
+		}
+	}
+	if roomInits.Load() != 1 {
+		t.Errorf("room_init calls = %d, want 1 (cached)", roomInits.Load())
+	}
+
+	if _, err := client.resolveUID(context.Background(), "300"); err == nil {
+		t.Error("resolveUID(300) error = nil, want error")
+	}
+	bilibiliRoomUIDsMu.RLock()
+	_, cached := bilibiliRoomUIDs["300"]
+	bilibiliRoomUIDsMu.RUnlock()
+	if cached {
+		t.Error("failed resolution was cached")
+	}
+}
@@ -0,0 +1,119 @@
+package platform
+
+import (
+	"sync"
+	"time"
+)
+
+// 默认熔断参数
+const (
+	DefaultBreakerThreshold = 5           // 连续失败多少次后熔断
+	DefaultBreakerCooldown  = time.Minute // 熔断后的冷却时间
+)
+
+// BreakerState 熔断器状态
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // 正常放行
+	BreakerOpen     BreakerState = "open"      // 熔断中，拒绝请求
+	BreakerHalfOpen BreakerState = "half_open" // 冷却结束，放行一个探测请求
+)
+
+// BreakerStatus 熔断器状态快照，用于健康检查
+type BreakerStatus struct {
+	State     BreakerState `json:"state"`
+	Failures  int          `json:"failures"`
+	OpenUntil *time.Time   `json:"open_until,omitempty"`
+}
+
+// Breaker 单个平台的熔断器
+// 连续失败达到阈值后熔断，冷却期内拒绝请求；冷却结束后放行一个探测请求，成功则恢复，失败则继续熔断
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// NewBreaker 创建熔断器
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow 返回当前是否允许发起请求
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		// 同一时间只放行一个探测请求
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// Success 记录一次成功请求，熔断器恢复正常
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure 记录一次失败请求，达到阈值或探测失败时熔断
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Release 放弃本次请求的结果（如调用方已取消），不计入成功或失败
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Status 返回熔断器当前状态
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{State: b.state, Failures: b.failures}
+	if b.state == BreakerOpen {
+		until := b.openedAt.Add(b.cooldown)
+		status.OpenUntil = &until
+	}
+	return status
+}
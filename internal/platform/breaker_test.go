@@ -0,0 +1,64 @@
+package platform
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	if !b.Allow() || b.Status().State != BreakerClosed {
+		t.Fatalf("breaker should stay closed below threshold, got %+v", b.Status())
+	}
+
+	b.Failure()
+	if b.Allow() || b.Status().State != BreakerOpen || b.Status().OpenUntil == nil {
+		t.Fatalf("breaker should open at threshold, got %+v", b.Status())
+	}
+
+	// 冷却结束后只放行一个探测请求
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatalf("breaker should allow a probe after cooldown")
+	}
+	if b.Allow() {
+		t.Errorf("breaker should allow only one probe at a time")
+	}
+
+	// 探测失败重新熔断
+	b.Failure()
+	if b.Allow() || b.Status().State != BreakerOpen {
+		t.Fatalf("failed probe should reopen breaker, got %+v", b.Status())
+	}
+
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatalf("breaker should allow a probe after cooldown")
+	}
+	b.Success()
+	if !b.Allow() || b.Status().State != BreakerClosed || b.Status().Failures != 0 {
+		t.Errorf("successful probe should close breaker, got %+v", b.Status())
+	}
+}
+
+func TestBreakerRelease(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := NewBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatalf("breaker should allow a probe after cooldown")
+	}
+
+	// 探测请求被取消时应允许下一个探测
+	b.Release()
+	if !b.Allow() {
+		t.Errorf("breaker should allow a new probe after release")
+	}
+}
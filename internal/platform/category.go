@@ -0,0 +1,24 @@
+package platform
+
+import (
+	"live-channels/internal/models"
+)
+
+// setCategory 设置直播分区，同时填充 Game 字段以兼容旧版前端
+// name 为空时不做任何修改
+func setCategory(status *models.StreamStatus, name, parent, url string) {
+	if name == "" {
+		return
+	}
+
+	if parent == name {
+		parent = ""
+	}
+
+	status.Game = name
+	status.Category = &models.Category{
+		Name:   name,
+		Parent: parent,
+		URL:    url,
+	}
+}
@@ -0,0 +1,227 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"live-channels/internal/models"
+	"regexp"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// CCAPIBaseURL 网易CC 接口默认地址
+	CCAPIBaseURL = "https://api.cc.163.com"
+	// CCWebBaseURL 网易CC 网页默认地址
+	CCWebBaseURL = "https://cc.163.com"
+
+	// CCEndpointWeb 网页接口的 endpoints 名称，base_url 对应 API 地址
+	CCEndpointWeb = "web"
+)
+
+// CCLivesResponse 主播开播信息响应，未开播时 data 中不包含该主播
+type CCLivesResponse struct {
+	Code string `json:"code"`
+	Data map[string]struct {
+		ChannelID int `json:"channel_id"` // 频道 ID，用于查询直播间详情
+		RoomID    int `json:"room_id"`
+	} `json:"data"`
+}
+
+// CCChannelResponse 直播间详情响应
+type CCChannelResponse struct {
+	Data []struct {
+		Title    string `json:"title"`    // 直播标题
+		Cover    string `json:"cover"`    // 直播封面
+		GameName string `json:"gamename"` // 游戏分类
+		Visitor  int    `json:"visitor"`  // 在线观众数
+		Nickname string `json:"nickname"` // 主播名字
+		Purl     string `json:"purl"`     // 主播头像
+	} `json:"data"`
+}
+
+// CCAnchorInfo 主播信息
+type CCAnchorInfo struct {
+	Nickname string `json:"nickname"` // 主播名字
+	Purl     string `json:"purl"`     // 主播头像
+}
+
+// CCRoomPageData 直播间页面 __NEXT_DATA__ 的数据结构，未开播时同样包含主播信息
+type CCRoomPageData struct {
+	Props struct {
+		PageProps struct {
+			RoomInfoInitData *struct {
+				Live CCAnchorInfo `json:"live"`
+			} `json:"roomInfoInitData"` // 主播不存在时为空
+		} `json:"pageProps"`
+	} `json:"props"`
+}
+
+func init() {
+	Register(Descriptor{
+		Name:        models.PlatformCC,
+		DisplayName: "网易CC",
+		Icon:        "https://cc.163.com/favicon.ico",
+		URLPatterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?cc\.163\.com/(\d+)(?:[/?#]|$)`),
+		},
+		Endpoints: []string{CCEndpointWeb},
+		New:       func(cfg models.PlatformConfig) StreamProvider { return NewCCClientWithConfig(cfg) },
+	})
+}
+
+// CCClient 网易CC 平台客户端
+type CCClient struct {
+	client     *resty.Client
+	apiBaseURL string
+	webBaseURL string
+}
+
+// NewCCClient 创建网易CC 客户端
+func NewCCClient() *CCClient {
+	return NewCCClientWithBaseURL(CCAPIBaseURL, CCWebBaseURL)
+}
+
+// NewCCClientWithBaseURL 使用指定地址创建网易CC 客户端（便于测试）
+func NewCCClientWithBaseURL(apiBaseURL, webBaseURL string) *CCClient {
+	return &CCClient{
+		client:     GetHTTPClient(),
+		apiBaseURL: apiBaseURL,
+		webBaseURL: webBaseURL,
+	}
+}
+
+// NewCCClientWithConfig 使用平台配置创建网易CC 客户端
+func NewCCClientWithConfig(cfg models.PlatformConfig) *CCClient {
+	c := NewCCClientWithBaseURL(baseURLOr(cfg, CCAPIBaseURL), endpointOr(cfg, CCEndpointWeb, CCWebBaseURL))
+	c.client = newHTTPClient(cfg)
+	return c
+}
+
+// GetStreamStatus 获取网易CC 直播状态
+// channelID 为直播间号，即 cc.163.com/{id}
+// API: https://api.cc.163.com/v1/activitylives/anchor/lives?anchor_ccid={id}
+func (c *CCClient) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	url := fmt.Sprintf("%s/v1/activitylives/anchor/lives?anchor_ccid=%s", c.apiBaseURL, channelID)
+
+	// 查询主播是否开播
+	resp, err := c.client.R().
+		SetContext(ctx).
+		Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cc live info: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch cc live info: %w", err)
+	}
+
+	var livesResp CCLivesResponse
+	if err := json.Unmarshal(resp.Body(), &livesResp); err != nil {
+		return nil, fmt.Errorf("failed to parse cc response: %w: %w", ErrParse, err)
+	}
+
+	if livesResp.Code != "OK" {
+		return nil, fmt.Errorf("cc api error: %s: %w", livesResp.Code, ErrUpstream)
+	}
+
+	status := &models.StreamStatus{
+		ChannelID:  channelID,
+		Name:       channelID,
+		Platform:   "cc",
+		State:      models.StateOffline,
+		ProfileURL: fmt.Sprintf("https://cc.163.com/%s/", channelID),
+		UpdatedAt:  time.Now().Unix(),
+	}
+
+	live, ok := livesResp.Data[channelID]
+	if !ok || live.ChannelID == 0 {
+		// 未开播时接口不返回直播间信息，从直播间页面获取主播名字和头像
+		page, err := c.getRoomPage(ctx, channelID)
+		if err != nil {
+			return nil, err
+		}
+		if page.Nickname != "" {
+			status.Name = page.Nickname
+		}
+		status.AvatarURL = page.Purl
+		return status, nil
+	}
+
+	// 获取直播间详情
+	channelResp, err := c.getChannelInfo(ctx, live.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	status.SetState(models.StateLive)
+	if len(channelResp.Data) > 0 {
+		info := channelResp.Data[0]
+		if info.Nickname != "" {
+			status.Name = info.Nickname
+		}
+		status.Title = info.Title
+		setCategory(status, info.GameName, "", "")
+		status.Viewers = info.Visitor
+		status.ThumbnailURL = info.Cover
+		status.AvatarURL = info.Purl
+	}
+
+	return status, nil
+}
+
+// getChannelInfo 获取直播间详情
+// API: https://cc.163.com/live/channel/?channelids={channelId}
+func (c *CCClient) getChannelInfo(ctx context.Context, channelID int) (*CCChannelResponse, error) {
+	url := fmt.Sprintf("%s/live/channel/?channelids=%d", c.webBaseURL, channelID)
+
+	resp, err := c.client.R().
+		SetContext(ctx).
+		Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cc channel info: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch cc channel info: %w", err)
+	}
+
+	var channelResp CCChannelResponse
+	if err := json.Unmarshal(resp.Body(), &channelResp); err != nil {
+		return nil, fmt.Errorf("failed to parse cc channel response: %w: %w", ErrParse, err)
+	}
+
+	return &channelResp, nil
+}
+
+// getRoomPage 解析直播间页面中的主播信息，主播不存在时返回 ErrChannelNotFound
+// 页面: https://cc.163.com/{id}/
+func (c *CCClient) getRoomPage(ctx context.Context, channelID string) (*CCAnchorInfo, error) {
+	resp, err := c.client.R().
+		SetContext(ctx).
+		Get(fmt.Sprintf("%s/%s/", c.webBaseURL, channelID))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cc room page: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch cc room page: %w", err)
+	}
+
+	data, err := extractField(resp.String(), `(?s)<script id="__NEXT_DATA__"[^>]*>(.*?)</script>`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cc room page: %w", err)
+	}
+	var page CCRoomPageData
+	if err := json.Unmarshal([]byte(data), &page); err != nil {
+		return nil, fmt.Errorf("failed to parse cc room page: %w: %w", ErrParse, err)
+	}
+
+	room := page.Props.PageProps.RoomInfoInitData
+	if room == nil || room.Live.Nickname == "" {
+		return nil, fmt.Errorf("cc anchor %s: %w", channelID, ErrChannelNotFound)
+	}
+	return &room.Live, nil
+}
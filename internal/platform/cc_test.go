@@ -0,0 +1,63 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCCGetStreamStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/activitylives/anchor/lives", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("anchor_ccid") == "361433" {
+			w.Write([]byte(`{"code":"OK","data":{"361433":{"channel_id":4567,"room_id":89}}}`))
+			return
+		}
+		w.Write([]byte(`{"code":"OK","data":{}}`))
+	})
+	mux.HandleFunc("/live/channel/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("channelids") != "4567" {
+			t.Errorf("unexpected channelids %q", r.URL.Query().Get("channelids"))
+		}
+		w.Write([]byte(`{"data":[{"title":"测试直播","cover":"https://example.com/cover.jpg","gamename":"第五人格","visitor":4321,"nickname":"测试主播","purl":"https://example.com/avatar.jpg"}]}`))
+	})
+	mux.HandleFunc("/100/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><script id="__NEXT_DATA__" type="application/json" crossorigin="">{"props":{"pageProps":{"roomInfoInitData":{"live":{"nickname":"离线主播","purl":"https://example.com/offline.jpg"}}}}}</script></html>`))
+	})
+	mux.HandleFunc("/200/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"roomInfoInitData":null}}}</script></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewCCClientWithBaseURL(server.URL, server.URL)
+
+	status, err := client.GetStreamStatus(context.Background(), "361433")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+	if !status.IsLive || status.Title != "测试直播" || status.Game != "第五人格" || status.Viewers != 4321 {
+		t.Errorf("unexpected live status: %+v", status)
+	}
+	if status.Name != "测试主播" || status.ThumbnailURL != "https://example.com/cover.jpg" {
+		t.Errorf("unexpected Name/ThumbnailURL: %q/%q", status.Name, status.ThumbnailURL)
+	}
+
+	offline, err := client.GetStreamStatus(context.Background(), "100")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() offline error = %v", err)
+	}
+	// 未开播时从直播间页面获取主播名字和头像
+	if offline.IsLive || offline.Name != "离线主播" || offline.AvatarURL != "https://example.com/offline.jpg" {
+		t.Errorf("unexpected offline status: %+v", offline)
+	}
+
+	// 主播不存在（页面无主播信息或返回 404）
+	for _, id := range []string{"200", "300"} {
+		if _, err := client.GetStreamStatus(context.Background(), id); !errors.Is(err, ErrChannelNotFound) {
+			t.Errorf("GetStreamStatus(%s) error = %v, want ErrChannelNotFound", id, err)
+		}
+	}
+}
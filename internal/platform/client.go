@@ -1,8 +1,11 @@
 package platform
 
 import (
+	"live-channels/internal/models"
 	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,27 +28,90 @@ func SetUserAgent(ua string) {
 // GetHTTPClient 返回共享的 HTTP 客户端单例
 func GetHTTPClient() *resty.Client {
 	once.Do(func() {
-		transport := &http.Transport{
-			MaxIdleConns:        10,
-			MaxIdleConnsPerHost: 5,
-			IdleConnTimeout:     30 * time.Second,
-			DialContext: (&net.Dialer{
-				Timeout:   5 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
-			TLSHandshakeTimeout: 5 * time.Second,
-		}
-
-		httpClient = resty.New().
-			SetTransport(transport).
-			SetHeader("User-Agent", userAgent). // 设置全局 UA
-			SetTimeout(5 * time.Second).
-			SetRetryCount(2).
-			SetRetryWaitTime(500 * time.Millisecond).
-			SetRetryMaxWaitTime(2 * time.Second).
-			AddRetryCondition(func(r *resty.Response, err error) bool {
-				return err != nil || r.StatusCode() >= 500
-			})
+		transport := newRateLimitTransport(newTransport(nil), models.PlatformConfig{})
+		httpClient = newRestyClient(transport, userAgent, 5*time.Second, 2)
 	})
 	return httpClient
 }
+
+// newHTTPClient 根据平台配置创建 HTTP 客户端
+// 未配置任何覆盖项时返回共享客户端，以复用连接池
+func newHTTPClient(cfg models.PlatformConfig) *resty.Client {
+	if cfg.Timeout <= 0 && cfg.RetryCount == nil && cfg.UserAgent == "" && len(cfg.Cookies) == 0 && cfg.Proxy == "" &&
+		cfg.RateLimit == 0 && cfg.Burst == 0 && cfg.MaxConcurrent == 0 {
+		return GetHTTPClient()
+	}
+
+	var proxy *url.URL
+	if cfg.Proxy != "" {
+		// 代理地址已在 NewProviders 中校验
+		proxy, _ = url.Parse(cfg.Proxy)
+	}
+
+	ua := userAgent
+	if cfg.UserAgent != "" {
+		ua = cfg.UserAgent
+	}
+	timeout := 5 * time.Second
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	retryCount := 2
+	if cfg.RetryCount != nil {
+		retryCount = *cfg.RetryCount
+	}
+
+	client := newRestyClient(newRateLimitTransport(newTransport(proxy), cfg), ua, timeout, retryCount)
+	for name, value := range cfg.Cookies {
+		client.SetCookie(&http.Cookie{Name: name, Value: value})
+	}
+	return client
+}
+
+// newTransport 创建 HTTP Transport，proxy 为 nil 时直连
+func newTransport(proxy *url.URL) *http.Transport {
+	transport := &http.Transport{
+		MaxIdleConns:        10,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     30 * time.Second,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return transport
+}
+
+// newRestyClient 使用统一的重试策略创建 resty 客户端
+func newRestyClient(transport http.RoundTripper, ua string, timeout time.Duration, retryCount int) *resty.Client {
+	return resty.New().
+		SetTransport(transport).
+		SetHeader("User-Agent", ua).
+		SetTimeout(timeout).
+		SetRetryCount(retryCount).
+		SetRetryWaitTime(500 * time.Millisecond).
+		SetRetryMaxWaitTime(2 * time.Second).
+		AddRetryCondition(func(r *resty.Response, err error) bool {
+			return err != nil || r.StatusCode() >= 500
+		})
+}
+
+// baseURLOr 返回配置的接口地址，未配置时返回默认地址
+func baseURLOr(cfg models.PlatformConfig, defaultURL string) string {
+	if cfg.BaseURL == "" {
+		return defaultURL
+	}
+	return strings.TrimRight(cfg.BaseURL, "/")
+}
+
+// endpointOr 返回 endpoints 中指定名称的接口地址，未配置时返回默认地址
+func endpointOr(cfg models.PlatformConfig, name, defaultURL string) string {
+	if cfg.Endpoints[name] == "" {
+		return defaultURL
+	}
+	return strings.TrimRight(cfg.Endpoints[name], "/")
+}
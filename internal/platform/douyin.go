@@ -0,0 +1,243 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"live-channels/internal/models"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DouyinBaseURL 抖音直播默认地址
+const DouyinBaseURL = "https://live.douyin.com"
+
+// errDouyinInvalidTTWID ttwid 无效时接口返回空响应体，只有这种情况才需要重新获取 ttwid
+var errDouyinInvalidTTWID = fmt.Errorf("douyin returned empty response: %w", ErrBlocked)
+
+// DouyinResponse 抖音直播间信息响应
+type DouyinResponse struct {
+	StatusCode int `json:"status_code"`
+	Data       struct {
+		Data []struct {
+			IDStr  string `json:"id_str"`
+			Status int    `json:"status"` // 直播状态：2 为在直播，4 为未开播
+			Title  string `json:"title"`
+			Cover  struct {
+				URLList []string `json:"url_list"`
+			} `json:"cover"`
+			RoomViewStats struct {
+				DisplayValue int    `json:"display_value"` // 在线观众数
+				DisplayLong  string `json:"display_long"`
+			} `json:"room_view_stats"`
+		} `json:"data"`
+		PartitionRoadMap struct {
+			Partition    douyinPartition `json:"partition"` // 一级分区
+			SubPartition struct {
+				Partition douyinPartition `json:"partition"` // 二级分区
+			} `json:"sub_partition"`
+		} `json:"partition_road_map"`
+		User struct {
+			Nickname    string `json:"nickname"`
+			AvatarThumb struct {
+				URLList []string `json:"url_list"`
+			} `json:"avatar_thumb"`
+		} `json:"user"`
+	} `json:"data"`
+}
+
+// douyinPartition 抖音直播分区
+type douyinPartition struct {
+	IDStr string `json:"id_str"`
+	Type  int    `json:"type"`
+	Title string `json:"title"`
+}
+
+func init() {
+	Register(Descriptor{
+		Name:        models.PlatformDouyin,
+		DisplayName: "抖音",
+		Icon:        "https://live.douyin.com/favicon.ico",
+		URLPatterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?live\.douyin\.com/(\d+)(?:[/?#]|$)`),
+		},
+		New: func(cfg models.PlatformConfig) StreamProvider { return NewDouyinClientWithConfig(cfg) },
+	})
+}
+
+// DouyinClient 抖音平台客户端
+type DouyinClient struct {
+	client  *resty.Client
+	baseURL string
+
+	mu    sync.Mutex // 保护 ttwid
+	ttwid string     // 抖音接口必须携带的访客 Cookie，首次请求时获取
+}
+
+// NewDouyinClient 创建抖音客户端
+func NewDouyinClient() *DouyinClient {
+	return NewDouyinClientWithBaseURL(DouyinBaseURL)
+}
+
+// NewDouyinClientWithBaseURL 使用指定地址创建抖音客户端（便于测试）
+func NewDouyinClientWithBaseURL(baseURL string) *DouyinClient {
+	return NewDouyinClientWithConfig(models.PlatformConfig{BaseURL: baseURL})
+}
+
+// NewDouyinClientWithConfig 使用平台配置创建抖音客户端
+func NewDouyinClientWithConfig(cfg models.PlatformConfig) *DouyinClient {
+	return &DouyinClient{
+		client:  newHTTPClient(cfg),
+		baseURL: baseURLOr(cfg, DouyinBaseURL),
+	}
+}
+
+// GetStreamStatus 获取抖音直播状态
+// channelID 为网页直播间号，即 live.douyin.com/{id}
+// API: https://live.douyin.com/webcast/room/web/enter/?web_rid={id}
+func (d *DouyinClient) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	douyinResp, err := d.getRoomInfo(ctx, channelID)
+	if errors.Is(err, errDouyinInvalidTTWID) {
+		// ttwid 已失效，重新获取后再试一次；限流、风控和网络错误不重试
+		d.resetTTWID()
+		douyinResp, err = d.getRoomInfo(ctx, channelID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if douyinResp.StatusCode != 0 {
+		return nil, fmt.Errorf("douyin api error: status_code %d: %w", douyinResp.StatusCode, ErrUpstream)
+	}
+
+	if len(douyinResp.Data.Data) == 0 {
+		return nil, fmt.Errorf("douyin room %s: %w", channelID, ErrChannelNotFound)
+	}
+
+	room := douyinResp.Data.Data[0]
+	user := douyinResp.Data.User
+
+	status := &models.StreamStatus{
+		ChannelID:    channelID,
+		Name:         user.Nickname,
+		Platform:     "douyin",
+		Title:        room.Title,
+		Viewers:      room.RoomViewStats.DisplayValue,
+		ViewersText:  room.RoomViewStats.DisplayLong,
+		ThumbnailURL: firstURL(room.Cover.URLList),
+		AvatarURL:    firstURL(user.AvatarThumb.URLList),
+		ProfileURL:   fmt.Sprintf("https://live.douyin.com/%s", channelID),
+		UpdatedAt:    time.Now().Unix(),
+	}
+
+	status.SetState(models.StateFromLive(room.Status == 2))
+
+	// 优先使用二级分区，没有时退回一级分区
+	roadMap := douyinResp.Data.PartitionRoadMap
+	if sub := roadMap.SubPartition.Partition; sub.Title != "" {
+		setCategory(status, sub.Title, roadMap.Partition.Title, douyinCategoryURL(sub))
+	} else {
+		setCategory(status, roadMap.Partition.Title, "", douyinCategoryURL(roadMap.Partition))
+	}
+
+	return status, nil
+}
+
+// getRoomInfo 请求直播间信息接口
+func (d *DouyinClient) getRoomInfo(ctx context.Context, channelID string) (*DouyinResponse, error) {
+	ttwid, err := d.getTTWID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"aid":              "6383",
+			"app_name":         "douyin_web",
+			"live_id":          "1",
+			"device_platform":  "web",
+			"language":         "zh-CN",
+			"browser_language": "zh-CN",
+			"browser_platform": "Win32",
+			"browser_name":     "Chrome",
+			"browser_version":  "120.0.0.0",
+			"web_rid":          channelID,
+		}).
+		SetHeader("Referer", d.baseURL+"/").
+		SetCookie(&http.Cookie{Name: "ttwid", Value: ttwid}).
+		Get(d.baseURL + "/webcast/room/web/enter/")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch douyin room info: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch douyin room info: %w", err)
+	}
+
+	if len(resp.Body()) == 0 {
+		return nil, errDouyinInvalidTTWID
+	}
+
+	var douyinResp DouyinResponse
+	if err := json.Unmarshal(resp.Body(), &douyinResp); err != nil {
+		return nil, fmt.Errorf("failed to parse douyin response: %w: %w", ErrParse, err)
+	}
+
+	return &douyinResp, nil
+}
+
+// getTTWID 获取访客 ttwid，首次调用时访问直播首页获取并缓存
+func (d *DouyinClient) getTTWID(ctx context.Context) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.ttwid != "" {
+		return d.ttwid, nil
+	}
+
+	resp, err := d.client.R().
+		SetContext(ctx).
+		Get(d.baseURL + "/")
+
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch douyin ttwid: %w: %w", ErrUpstream, err)
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "ttwid" && cookie.Value != "" {
+			d.ttwid = cookie.Value
+			return d.ttwid, nil
+		}
+	}
+
+	return "", fmt.Errorf("douyin ttwid cookie not found: %w", ErrUpstream)
+}
+
+// resetTTWID 清除缓存的 ttwid
+func (d *DouyinClient) resetTTWID() {
+	d.mu.Lock()
+	d.ttwid = ""
+	d.mu.Unlock()
+}
+
+// douyinCategoryURL 生成分区页面链接
+func douyinCategoryURL(partition douyinPartition) string {
+	if partition.IDStr == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://live.douyin.com/category/%d_%s", partition.Type, partition.IDStr)
+}
+
+// firstURL 返回 URL 列表中的第一个地址
+func firstURL(urls []string) string {
+	if len(urls) == 0 {
+		return ""
+	}
+	return urls[0]
+}
@@ -0,0 +1,160 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"live-channels/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDouyinGetStreamStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "ttwid", Value: "test-ttwid"})
+	})
+	mux.HandleFunc("/webcast/room/web/enter/", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("ttwid")
+		if err != nil || cookie.Value != "test-ttwid" {
+			// 与真实接口一致：缺少 ttwid 时返回空响应
+			return
+		}
+		if r.URL.Query().Get("web_rid") != "123456" {
+			w.Write([]byte(`{"status_code":0,"data":{"data":[]}}`))
+			return
+		}
+		w.Write([]byte(`{
+			"status_code": 0,
+			"data": {
+				"data": [{
+					"id_str": "7300000000000000000",
+					"status": 2,
+					"title": "测试直播",
+					"cover": {"url_list": ["https://example.com/cover.jpg"]},
+					"room_view_stats": {"display_value": 12345, "display_long": "1.2万"}
+				}],
+				"partition_road_map": {
+					"partition": {"id_str": "1", "type": 1, "title": "网游"},
+					"sub_partition": {"partition": {"id_str": "1010", "type": 1, "title": "英雄联盟"}}
+				},
+				"user": {
+					"nickname": "测试主播",
+					"avatar_thumb": {"url_list": ["https://example.com/avatar.jpg"]}
+				}
+			}
+		}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewDouyinClientWithBaseURL(server.URL)
+	status, err := client.GetStreamStatus(context.Background(), "123456")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+
+	if !status.IsLive {
+		t.Errorf("IsLive = false, want true")
+	}
+	if status.Name != "测试主播" || status.Title != "测试直播" {
+		t.Errorf("Name/Title = %q/%q", status.Name, status.Title)
+	}
+	if status.Viewers != 12345 || status.ViewersText != "1.2万" {
+		t.Errorf("Viewers/ViewersText = %d/%q, want 12345/\"1.2万\"", status.Viewers, status.ViewersText)
+	}
+	if status.ThumbnailURL != "https://example.com/cover.jpg" || status.AvatarURL != "https://example.com/avatar.jpg" {
+		t.Errorf("ThumbnailURL/AvatarURL = %q/%q", status.ThumbnailURL, status.AvatarURL)
+	}
+	if status.Category == nil || status.Category.Name != "英雄联盟" || status.Category.Parent != "网游" || status.Game != "英雄联盟" {
+		t.Errorf("Category = %+v, Game = %q", status.Category, status.Game)
+	}
+
+	if _, err := client.GetStreamStatus(context.Background(), "000000"); err == nil {
+		t.Errorf("GetStreamStatus() for missing room should return error")
+	}
+}
+
+func TestDouyinTTWIDRetry(t *testing.T) {
+	var homeRequests, roomRequests atomic.Int32
+	var roomStatus atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		n := homeRequests.Add(1)
+		http.SetCookie(w, &http.Cookie{Name: "ttwid", Value: fmt.Sprintf("ttwid-%d", n)})
+	})
+	mux.HandleFunc("/webcast/room/web/enter/", func(w http.ResponseWriter, r *http.Request) {
+		roomRequests.Add(1)
+		if code := int(roomStatus.Load()); code != 0 {
+			w.WriteHeader(code)
+			return
+		}
+		// 只接受第二次获取的 ttwid，第一次获取的视为已失效
+		if cookie, err := r.Cookie("ttwid"); err != nil || cookie.Value != "ttwid-2" {
+			return
+		}
+		w.Write([]byte(`{"status_code":0,"data":{"data":[{"status":4,"title":"未开播"}],"user":{"nickname":"主播"}}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	retry := 0
+	client := NewDouyinClientWithConfig(models.PlatformConfig{BaseURL: server.URL, RetryCount: &retry})
+
+	// 空响应体表示 ttwid 无效，重新获取后重试一次
+	if _, err := client.GetStreamStatus(context.Background(), "123456"); err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+	if homeRequests.Load() != 2 || roomRequests.Load() != 2 {
+		t.Errorf("home/room requests = %d/%d, want 2/2", homeRequests.Load(), roomRequests.Load())
+	}
+
+	// 限流和风控不重置 ttwid，也不重试
+	for _, code := range []int{http.StatusTooManyRequests, http.StatusForbidden} {
+		roomStatus.Store(int32(code))
+		homeRequests.Store(0)
+		roomRequests.Store(0)
+		if _, err := client.GetStreamStatus(context.Background(), "123456"); err == nil {
+			t.Fatalf("GetStreamStatus() with HTTP %d error = nil", code)
+		}
+		if homeRequests.Load() != 0 || roomRequests.Load() != 1 {
+			t.Errorf("HTTP %d: home/room requests = %d/%d, want 0/1", code, homeRequests.Load(), roomRequests.Load())
+		}
+	}
+
+	// ttwid 保存在客户端实例上，新客户端需要自行获取
+	roomStatus.Store(0)
+	homeRequests.Store(0)
+	other := NewDouyinClientWithConfig(models.PlatformConfig{BaseURL: server.URL, RetryCount: &retry})
+	if _, err := other.getTTWID(context.Background()); err != nil {
+		t.Fatalf("getTTWID() error = %v", err)
+	}
+	if homeRequests.Load() != 1 || other.ttwid == client.ttwid {
+		t.Errorf("new client ttwid = %q, home requests = %d, want own ttwid", other.ttwid, homeRequests.Load())
+	}
+}
+
+func TestDouyinNetworkErrorDoesNotRetry(t *testing.T) {
+	var homeRequests atomic.Int32
+	home := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		homeRequests.Add(1)
+		http.SetCookie(w, &http.Cookie{Name: "ttwid", Value: "test-ttwid"})
+	}))
+	defer home.Close()
+
+	retry := 0
+	client := NewDouyinClientWithConfig(models.PlatformConfig{BaseURL: home.URL, RetryCount: &retry})
+	if _, err := client.getTTWID(context.Background()); err != nil {
+		t.Fatalf("getTTWID() error = %v", err)
+	}
+	home.Close()
+
+	if _, err := client.GetStreamStatus(context.Background(), "123456"); !errors.Is(err, ErrUpstream) {
+		t.Errorf("GetStreamStatus() network error = %v, want ErrUpstream", err)
+	}
+	if client.ttwid != "test-ttwid" || homeRequests.Load() != 1 {
+		t.Errorf("ttwid = %q after network error, want kept", client.ttwid)
+	}
+}
@@ -1,15 +1,20 @@
 package platform
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"live-channels/internal/models"
-	"strconv"
+	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 )
 
+// DouyuBaseURL 斗鱼默认地址
+const DouyuBaseURL = "https://www.douyu.com"
+
 // DouyuResponse 斗鱼直播间信息响应
 type DouyuResponse struct {
 	Room struct {
@@ -19,58 +24,106 @@ type DouyuResponse struct {
 		RoomName   string `json:"room_name"`   // 直播间名称
 		RoomPic    string `json:"room_pic"`    // 直播间封面
 		VideoLoop  int    `json:"videoLoop"`   // 视频循环标志：0 为正常直播，1 为循环播放
+		Cate1Name  string `json:"cate1Name"`   // 一级分类
+		Cate2Name  string `json:"cate2Name"`   // 二级分类
+		ShowTime   int64  `json:"show_time"`   // 开播时间（Unix 时间戳）
 		RoomBizAll struct {
 			Hot string `json:"hot"` // 在线观众数
 		} `json:"room_biz_all"`
 	} `json:"room"`
 }
 
+func init() {
+	Register(Descriptor{
+		Name:        models.PlatformDouyu,
+		DisplayName: "斗鱼",
+		Icon:        "https://www.douyu.com/favicon.ico",
+		URLPatterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?(?:www\.|m\.)?douyu\.com/(\d+)(?:[/?#]|$)`),
+			// 专题页等活动页面通过 rid 参数指定直播间
+			regexp.MustCompile(`^(?:https?://)?(?:www\.|m\.)?douyu\.com/[^?#]*\?(?:[^#]*&)?rid=(\d+)(?:[&#]|$)`),
+		},
+		New: func(cfg models.PlatformConfig) StreamProvider { return NewDouyuClientWithConfig(cfg) },
+	})
+}
+
 // DouyuClient 斗鱼平台客户端
 type DouyuClient struct {
-	client *resty.Client
+	client  *resty.Client
+	baseURL string
 }
 
 // NewDouyuClient 创建斗鱼客户端
 func NewDouyuClient() *DouyuClient {
+	return NewDouyuClientWithConfig(models.PlatformConfig{})
+}
+
+// NewDouyuClientWithBaseURL 使用指定地址创建斗鱼客户端（便于测试）
+func NewDouyuClientWithBaseURL(baseURL string) *DouyuClient {
+	return NewDouyuClientWithConfig(models.PlatformConfig{BaseURL: baseURL})
+}
+
+// NewDouyuClientWithConfig 使用平台配置创建斗鱼客户端
+func NewDouyuClientWithConfig(cfg models.PlatformConfig) *DouyuClient {
 	return &DouyuClient{
-		client: GetHTTPClient(),
+		client:  newHTTPClient(cfg),
+		baseURL: baseURLOr(cfg, DouyuBaseURL),
 	}
 }
 
 // GetStreamStatus 获取斗鱼直播状态
 // API: https://www.douyu.com/betard/{roomId}
-func (d *DouyuClient) GetStreamStatus(channelID string) (*models.StreamStatus, error) {
-	url := fmt.Sprintf("https://www.douyu.com/betard/%s", channelID)
+func (d *DouyuClient) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	apiURL := fmt.Sprintf("%s/betard/%s", d.baseURL, channelID)
 
 	// 获取直播间信息
 	resp, err := d.client.R().
-		Get(url)
+		SetContext(ctx).
+		Get(apiURL)
 
 	if err != nil {
+		return nil, fmt.Errorf("failed to fetch douyu room info: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
 		return nil, fmt.Errorf("failed to fetch douyu room info: %w", err)
 	}
 
 	var douyuResp DouyuResponse
 	if err := json.Unmarshal(resp.Body(), &douyuResp); err != nil {
-		return nil, fmt.Errorf("failed to parse douyu response: %w", err)
+		return nil, fmt.Errorf("failed to parse douyu response: %w: %w", ErrParse, err)
 	}
 
-	// 判断是否直播：show_status == 1 且 videoLoop == 0
-	isLive := douyuResp.Room.ShowStatus == 1 && douyuResp.Room.VideoLoop == 0
-	viewers, _ := strconv.Atoi(douyuResp.Room.RoomBizAll.Hot)
+	// 判断直播状态：show_status == 1 且 videoLoop == 0 为直播，videoLoop == 1 为轮播
+	state := models.StateOffline
+	if douyuResp.Room.ShowStatus == 1 {
+		if douyuResp.Room.VideoLoop == 0 {
+			state = models.StateLive
+		} else {
+			state = models.StateReplay
+		}
+	}
+	// 热度可能是 "12.3万" 这样的展示文本，无法解析时观众数记为 0，原文保留在 ViewersText
+	viewers, _ := parseCNNumber(douyuResp.Room.RoomBizAll.Hot)
 
 	status := &models.StreamStatus{
 		ChannelID:    channelID,
 		Name:         douyuResp.Room.OwnerName,
 		Platform:     "douyu",
-		IsLive:       isLive,
 		Title:        douyuResp.Room.RoomName,
-		Viewers:      viewers,
+		Viewers:      int(viewers),
+		ViewersText:  douyuResp.Room.RoomBizAll.Hot,
 		ThumbnailURL: douyuResp.Room.RoomPic,
 		AvatarURL:    douyuResp.Room.AvatarMid,
 		ProfileURL:   fmt.Sprintf("https://www.douyu.com/%s", channelID),
 		UpdatedAt:    time.Now().Unix(),
 	}
+	status.SetState(state)
+	if status.IsLive {
+		status.LiveSince = douyuResp.Room.ShowTime
+	}
+	// 斗鱼分类页使用拼音简称，接口中没有，改为链接到搜索页
+	setCategory(status, douyuResp.Room.Cate2Name, douyuResp.Room.Cate1Name,
+		"https://www.douyu.com/search/?kw="+url.QueryEscape(douyuResp.Room.Cate2Name))
 
 	return status, nil
 }
@@ -0,0 +1,29 @@
+package platform
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDouyuGetStreamStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/betard/9999" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.Write([]byte(`{"room":{"show_status":1,"videoLoop":0,"owner_name":"测试主播","room_name":"测试直播","cate1Name":"网游竞技","cate2Name":"英雄联盟","show_time":1700000000,"room_biz_all":{"hot":"12.3万"}}}`))
+	}))
+	defer server.Close()
+
+	status, err := NewDouyuClientWithBaseURL(server.URL).GetStreamStatus(context.Background(), "9999")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+	if !status.IsLive || status.Name != "测试主播" || status.LiveSince != 1700000000 {
+		t.Errorf("IsLive/Name/LiveSince = %v/%q/%d", status.IsLive, status.Name, status.LiveSince)
+	}
+	if status.Viewers != 123000 || status.ViewersText != "12.3万" {
+		t.Errorf("Viewers/ViewersText = %d/%q, want 123000/\"12.3万\"", status.Viewers, status.ViewersText)
+	}
+}
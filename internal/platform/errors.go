@@ -0,0 +1,57 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// 平台错误分类，各平台返回的错误通过 %w 包装，可使用 errors.Is 判断
+var (
+	ErrChannelNotFound = errors.New("channel not found")   // 频道不存在或配置错误
+	ErrRateLimited     = errors.New("rate limited")        // 请求过于频繁
+	ErrBlocked         = errors.New("blocked by upstream") // 被风控拦截（如 B 站 -412/-352）
+	ErrParse           = errors.New("malformed response")  // 响应无法解析，通常是平台页面或接口改版
+	ErrUpstream        = errors.New("upstream error")      // 网络错误或平台接口返回错误
+	ErrNotConfigured   = errors.New("not configured")      // 缺少凭据等本地配置，请求未发出
+)
+
+// ErrorKind 返回错误分类名称，用于 API 输出
+func ErrorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrChannelNotFound):
+		return "not_found"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrBlocked):
+		return "blocked"
+	case errors.Is(err, ErrParse):
+		return "parse"
+	case errors.Is(err, ErrNotConfigured):
+		return "config"
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return "timeout"
+	case errors.Is(err, ErrUpstream):
+		return "upstream"
+	}
+	return "unknown"
+}
+
+// statusError 根据 HTTP 状态码返回对应分类的错误，成功响应返回 nil
+func statusError(resp *resty.Response) error {
+	code := resp.StatusCode()
+	switch {
+	case code < http.StatusBadRequest:
+		return nil
+	case code == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", resp.Status(), ErrChannelNotFound)
+	case code == http.StatusTooManyRequests:
+		return fmt.Errorf("%s: %w", resp.Status(), ErrRateLimited)
+	case code == http.StatusForbidden || code == http.StatusPreconditionFailed:
+		return fmt.Errorf("%s: %w", resp.Status(), ErrBlocked)
+	}
+	return fmt.Errorf("%s: %w", resp.Status(), ErrUpstream)
+}
@@ -0,0 +1,69 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind string
+	}{
+		{fmt.Errorf("douyin room 1: %w", ErrChannelNotFound), "not_found"},
+		{fmt.Errorf("429: %w", ErrRateLimited), "rate_limited"},
+		{fmt.Errorf("bilibili api error -412: %w", ErrBlocked), "blocked"},
+		{fmt.Errorf("failed to parse: %w: %w", ErrParse, fmt.Errorf("eof")), "parse"},
+		{fmt.Errorf("failed to fetch: %w: %w", ErrUpstream, context.DeadlineExceeded), "timeout"},
+		{fmt.Errorf("failed to fetch: %w", ErrUpstream), "upstream"},
+		{fmt.Errorf("twitch client credentials: %w", ErrNotConfigured), "config"},
+		{fmt.Errorf("something else"), "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := ErrorKind(tt.err); got != tt.kind {
+			t.Errorf("ErrorKind(%v) = %q, want %q", tt.err, got, tt.kind)
+		}
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		code int
+		kind string
+	}{
+		{http.StatusOK, ""},
+		{http.StatusNotFound, "not_found"},
+		{http.StatusTooManyRequests, "rate_limited"},
+		{http.StatusForbidden, "blocked"},
+		{http.StatusPreconditionFailed, "blocked"},
+		{http.StatusBadGateway, "upstream"},
+	}
+
+	client := newRestyClient(http.DefaultTransport, "test", time.Second, 0)
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.code)
+		}))
+		resp, err := client.R().Get(server.URL)
+		server.Close()
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		err = statusError(resp)
+		if tt.kind == "" {
+			if err != nil {
+				t.Errorf("statusError(%d) = %v, want nil", tt.code, err)
+			}
+			continue
+		}
+		if got := ErrorKind(err); got != tt.kind {
+			t.Errorf("statusError(%d) kind = %q, want %q", tt.code, got, tt.kind)
+		}
+	}
+}
@@ -1,24 +1,66 @@
 package platform
 
 import (
+	"context"
+	"fmt"
 	"live-channels/internal/models"
+	"net/url"
+	"slices"
 )
 
 // StreamProvider 直播平台接口
 type StreamProvider interface {
-	GetStreamStatus(channelID string) (*models.StreamStatus, error)
+	GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error)
 }
 
-// Factory 工厂函数
+// UIDStreamProvider 支持通过用户 UID 查询直播状态的平台
+type UIDStreamProvider interface {
+	GetStreamStatusByUID(ctx context.Context, uid string) (*models.StreamStatus, error)
+}
+
+// BatchStreamProvider 支持一次请求查询多个频道直播状态的平台
+// 返回结果以 ChannelConfig.Key() 为键，查询失败的频道不包含在结果中
+type BatchStreamProvider interface {
+	GetStreamStatuses(ctx context.Context, channels []models.ChannelConfig) (map[string]*models.StreamStatus, error)
+}
+
+// CreateProvider 根据平台类型创建对应的客户端，未注册的平台返回 nil
 func CreateProvider(platform models.Platform) StreamProvider {
-	switch platform {
-	case models.PlatformBilibili:
-		return NewBilibiliClient()
-	case models.PlatformDouyu:
-		return NewDouyuClient()
-	case models.PlatformHuya:
-		return NewHuyaClient()
-	default:
+	d, ok := Lookup(platform)
+	if !ok {
 		return nil
 	}
+	return d.New(models.PlatformConfig{})
+}
+
+// NewProviders 为所有已注册平台创建客户端，应用配置文件中对应平台的配置
+// 客户端在启动时创建一次，之后由 Service 复用
+func NewProviders(configs map[models.Platform]models.PlatformConfig) (map[models.Platform]StreamProvider, error) {
+	for name, cfg := range configs {
+		d, ok := Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown platform %q in platforms config", name)
+		}
+		for key := range cfg.Endpoints {
+			if !slices.Contains(d.Endpoints, key) {
+				return nil, fmt.Errorf("unknown endpoint %q for platform %s", key, name)
+			}
+		}
+		for key := range cfg.Options {
+			if !slices.Contains(d.Options, key) {
+				return nil, fmt.Errorf("unknown option %q for platform %s", key, name)
+			}
+		}
+		if cfg.Proxy != "" {
+			if u, err := url.Parse(cfg.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+				return nil, fmt.Errorf("invalid proxy %q for platform %s", cfg.Proxy, name)
+			}
+		}
+	}
+
+	providers := make(map[models.Platform]StreamProvider)
+	for _, d := range Descriptors() {
+		providers[d.Name] = d.New(configs[d.Name])
+	}
+	return providers, nil
 }
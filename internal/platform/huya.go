@@ -1,107 +1,293 @@
 package platform
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"live-channels/internal/models"
+	"net/http"
 	"regexp"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 )
 
+const (
+	// HuyaBaseURL 虎牙默认地址
+	HuyaBaseURL = "https://www.huya.com"
+	// HuyaMobileBaseURL 虎牙移动端接口默认地址
+	HuyaMobileBaseURL = "https://mp.huya.com"
+
+	// HuyaEndpointMobile 移动端接口的 endpoints 名称，base_url 对应网页地址
+	HuyaEndpointMobile = "mobile"
+)
+
+// HuyaRoomData 直播间页面中 TT_ROOM_DATA 的字段
+type HuyaRoomData struct {
+	State        string    `json:"state"` // ON 为直播中，OFF 为未开播，REPLAY 为重播
+	IsOn         bool      `json:"isOn"`
+	IsReplay     bool      `json:"isReplay"` // 重播时 isOn 同样为 true
+	Introduction string    `json:"introduction"`
+	Screenshot   string    `json:"screenshot"`
+	GameFullName string    `json:"gameFullName"`
+	Gid          flexInt   `json:"gid"`
+	StartTime    flexInt   `json:"startTime"`
+	TotalCount   flexCount `json:"totalCount"`
+}
+
+// HuyaProfileInfo 直播间页面中 TT_PROFILE_INFO 的字段
+type HuyaProfileInfo struct {
+	Nick   string `json:"nick"`
+	Avatar string `json:"avatar"`
+}
+
+// HuyaGameLiveInfo 直播信息，页面 hyPlayerConfig.stream 和移动端接口共用
+type HuyaGameLiveInfo struct {
+	Nick          string    `json:"nick"`
+	Introduction  string    `json:"introduction"`
+	Avatar180     string    `json:"avatar180"`
+	Screenshot    string    `json:"screenshot"`
+	GameFullName  string    `json:"gameFullName"`
+	Gid           flexInt   `json:"gid"`
+	StartTime     flexInt   `json:"startTime"`
+	AttendeeCount flexCount `json:"attendeeCount"`
+	TotalCount    flexCount `json:"totalCount"`
+}
+
+// HuyaPlayerStream 直播间页面中 hyPlayerConfig.stream 的字段，未开播时为 null
+type HuyaPlayerStream struct {
+	Data []struct {
+		GameLiveInfo HuyaGameLiveInfo `json:"gameLiveInfo"`
+	} `json:"data"`
+}
+
+// HuyaMobileResponse 移动端直播间接口响应，出错时 data 为空字符串
+type HuyaMobileResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// HuyaMobileRoom 移动端直播间接口的 data 字段
+type HuyaMobileRoom struct {
+	RealLiveStatus string `json:"realLiveStatus"` // ON / OFF / REPLAY
+	LiveStatus     string `json:"liveStatus"`
+	ProfileInfo    struct {
+		Nick      string `json:"nick"`
+		Avatar180 string `json:"avatar180"`
+	} `json:"profileInfo"`
+	LiveData HuyaGameLiveInfo `json:"liveData"`
+}
+
+func init() {
+	Register(Descriptor{
+		Name:        models.PlatformHuya,
+		DisplayName: "虎牙",
+		Icon:        "https://www.huya.com/favicon.ico",
+		URLPatterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?(?:www\.|m\.)?huya\.com/(\w+)(?:[/?#]|$)`),
+		},
+		// 分类、列表、视频、搜索等页面
+		ReservedIDs: []string{"g", "l", "e", "video", "search", "myfollow", "subscribe", "cache", "info"},
+		Endpoints:   []string{HuyaEndpointMobile},
+		New:         func(cfg models.PlatformConfig) StreamProvider { return NewHuyaClientWithConfig(cfg) },
+	})
+}
+
 // HuyaClient 虎牙平台客户端
 type HuyaClient struct {
-	client *resty.Client
+	client        *resty.Client
+	baseURL       string
+	mobileBaseURL string
 }
 
 // NewHuyaClient 创建虎牙客户端
 func NewHuyaClient() *HuyaClient {
+	return NewHuyaClientWithBaseURL(HuyaBaseURL, HuyaMobileBaseURL)
+}
+
+// NewHuyaClientWithBaseURL 使用指定地址创建虎牙客户端（便于测试）
+func NewHuyaClientWithBaseURL(baseURL, mobileBaseURL string) *HuyaClient {
 	return &HuyaClient{
-		client: GetHTTPClient(),
+		client:        GetHTTPClient(),
+		baseURL:       baseURL,
+		mobileBaseURL: mobileBaseURL,
 	}
 }
 
+// NewHuyaClientWithConfig 使用平台配置创建虎牙客户端
+func NewHuyaClientWithConfig(cfg models.PlatformConfig) *HuyaClient {
+	c := NewHuyaClientWithBaseURL(baseURLOr(cfg, HuyaBaseURL), endpointOr(cfg, HuyaEndpointMobile, HuyaMobileBaseURL))
+	c.client = newHTTPClient(cfg)
+	return c
+}
+
 // GetStreamStatus 获取虎牙直播状态
-// 直接访问直播间页面，从 HTML 中解析 JSON 数据
+// 优先解析直播间页面内嵌的 JSON 数据，页面获取或解析失败时使用移动端接口
 // API: https://www.huya.com/{roomId}
-func (h *HuyaClient) GetStreamStatus(channelID string) (*models.StreamStatus, error) {
-	url := fmt.Sprintf("https://www.huya.com/%s", channelID)
+// API: https://mp.huya.com/cache.php?m=Live&do=profileRoom&roomid={roomId}
+func (h *HuyaClient) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	status, pageErr := h.getStatusFromPage(ctx, channelID)
+	if pageErr == nil {
+		return status, nil
+	}
+	if ctx.Err() != nil {
+		return nil, pageErr
+	}
 
-	// 获取直播间页面
+	status, err := h.getStatusFromMobile(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("huya page: %v; mobile api: %w", pageErr, err)
+	}
+	return status, nil
+}
+
+// getStatusFromPage 从直播间页面的 TT_ROOM_DATA、TT_PROFILE_INFO 和 hyPlayerConfig 中解析直播状态
+func (h *HuyaClient) getStatusFromPage(ctx context.Context, channelID string) (*models.StreamStatus, error) {
 	resp, err := h.client.R().
-		Get(url)
+		SetContext(ctx).
+		Get(fmt.Sprintf("%s/%s", h.baseURL, channelID))
 
 	if err != nil {
+		return nil, fmt.Errorf("failed to fetch huya room page: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
 		return nil, fmt.Errorf("failed to fetch huya room page: %w", err)
 	}
 
 	body := string(resp.Body())
 
-	// 从 HTML 中解析出所需字段
-	name, err := extractField(body, `"nick":"([^"]*)"`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract nick: %w", err)
+	var room HuyaRoomData
+	if err := extractJSONAssign(body, "TT_ROOM_DATA", &room); err != nil {
+		return nil, fmt.Errorf("failed to parse huya room data: %w", err)
 	}
 
-	title, err := extractField(body, `"introduction":"([^"]*)"`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract introduction: %w", err)
+	var profile HuyaProfileInfo
+	if err := extractJSONAssign(body, "TT_PROFILE_INFO", &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse huya profile info: %w", err)
 	}
 
-	isOnStr, err := extractField(body, `"isOn":(\w+)`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract isOn: %w", err)
+	// 播放器配置中的直播信息包含观众数和大尺寸头像，未开播时为空，忽略错误
+	var live HuyaGameLiveInfo
+	if idx := strings.Index(body, "hyPlayerConfig"); idx >= 0 {
+		var stream HuyaPlayerStream
+		if err := extractJSONAssign(body[idx:], "stream", &stream); err == nil && len(stream.Data) > 0 {
+			live = stream.Data[0].GameLiveInfo
+		}
 	}
 
-	viewersStr, err := extractField(body, `"attendeeCount":(\d+)`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract attendeeCount: %w", err)
+	// 页面数据优先，播放器配置补充
+	live.Nick = firstNonEmpty(profile.Nick, live.Nick)
+	live.Avatar180 = firstNonEmpty(live.Avatar180, profile.Avatar)
+	live.Introduction = firstNonEmpty(room.Introduction, live.Introduction)
+	live.Screenshot = firstNonEmpty(room.Screenshot, live.Screenshot)
+	live.GameFullName = firstNonEmpty(room.GameFullName, live.GameFullName)
+	if room.Gid != 0 {
+		live.Gid = room.Gid
+	}
+	if room.StartTime != 0 {
+		live.StartTime = room.StartTime
+	}
+	if live.TotalCount.Value == 0 {
+		live.TotalCount = room.TotalCount
 	}
 
-	avatar, err := extractField(body, `"avatar180":"([^"]*)"`)
-	if err != nil {
-		// 头像可能不存在，使用空字符串
-		avatar = ""
+	state := models.StateFromLive(room.IsOn)
+	if room.State == "REPLAY" || (state == models.StateLive && room.IsReplay) {
+		state = models.StateReplay
 	}
 
-	thumbnail, err := extractField(body, `"screenshot":"([^"]*)"`)
+	return newHuyaStatus(channelID, state, live), nil
+}
+
+// getStatusFromMobile 通过移动端接口获取直播状态
+func (h *HuyaClient) getStatusFromMobile(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	resp, err := h.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"m":      "Live",
+			"do":     "profileRoom",
+			"roomid": channelID,
+		}).
+		Get(h.mobileBaseURL + "/cache.php")
+
 	if err != nil {
-		// 缩略图可能不存在，使用空字符串
-		thumbnail = ""
+		return nil, fmt.Errorf("failed to fetch huya mobile room info: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch huya mobile room info: %w", err)
+	}
+
+	var mobileResp HuyaMobileResponse
+	if err := json.Unmarshal(resp.Body(), &mobileResp); err != nil {
+		return nil, fmt.Errorf("failed to parse huya mobile response: %w: %w", ErrParse, err)
+	}
+
+	if mobileResp.Status != http.StatusOK {
+		if strings.Contains(mobileResp.Message, "不存在") {
+			return nil, fmt.Errorf("huya room %s: %s: %w", channelID, mobileResp.Message, ErrChannelNotFound)
+		}
+		return nil, fmt.Errorf("huya api error: %d %s: %w", mobileResp.Status, mobileResp.Message, ErrUpstream)
+	}
+
+	var room HuyaMobileRoom
+	if err := json.Unmarshal(mobileResp.Data, &room); err != nil {
+		return nil, fmt.Errorf("failed to parse huya mobile room data: %w: %w", ErrParse, err)
+	}
+
+	live := room.LiveData
+	live.Nick = firstNonEmpty(room.ProfileInfo.Nick, live.Nick)
+	live.Avatar180 = firstNonEmpty(room.ProfileInfo.Avatar180, live.Avatar180)
+
+	liveStatus := firstNonEmpty(room.RealLiveStatus, room.LiveStatus)
+	state := models.StateFromLive(liveStatus == "ON")
+	if liveStatus == "REPLAY" {
+		state = models.StateReplay
 	}
 
-	// 转换类型
-	isLive := isOnStr == "true"
-	viewers, _ := strconv.Atoi(viewersStr)
+	return newHuyaStatus(channelID, state, live), nil
+}
+
+// newHuyaStatus 根据解析出的直播信息生成直播状态
+func newHuyaStatus(channelID string, state models.StreamState, live HuyaGameLiveInfo) *models.StreamStatus {
+	viewers := live.AttendeeCount
+	if viewers.Value == 0 {
+		viewers = live.TotalCount
+	}
 
 	status := &models.StreamStatus{
 		ChannelID:    channelID,
-		Name:         name,
+		Name:         live.Nick,
 		Platform:     "huya",
-		IsLive:       isLive,
-		Title:        title,
-		Viewers:      viewers,
-		ThumbnailURL: thumbnail,
-		AvatarURL:    avatar,
+		Title:        live.Introduction,
+		Viewers:      int(viewers.Value),
+		ViewersText:  viewers.Text,
+		ThumbnailURL: live.Screenshot,
+		AvatarURL:    live.Avatar180,
 		ProfileURL:   fmt.Sprintf("https://www.huya.com/%s", channelID),
 		UpdatedAt:    time.Now().Unix(),
 	}
+	status.SetState(state)
 
-	return status, nil
-}
-
-// extractField 从 HTML 中使用正则表达式提取字段
-func extractField(html, pattern string) (string, error) {
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		return "", fmt.Errorf("invalid regex pattern: %w", err)
+	categoryURL := ""
+	if live.Gid != 0 {
+		categoryURL = fmt.Sprintf("https://www.huya.com/g/%d", live.Gid)
 	}
-
-	matches := re.FindStringSubmatch(html)
-	if len(matches) < 2 {
-		return "", fmt.Errorf("field not found with pattern: %s", pattern)
+	setCategory(status, live.GameFullName, "", categoryURL)
+	if status.IsLive {
+		status.LiveSince = int64(live.StartTime)
 	}
 
-	return matches[1], nil
+	return status
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
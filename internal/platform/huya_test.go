@@ -0,0 +1,76 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const huyaTestPage = `<html><head><script>var TT_META_DATA = {"nick":"错误的昵称","introduction":"错误的标题"};</script></head><body>
+<script>
+var TT_ROOM_DATA = {"type":"NORMAL","state":"ON","isOn":true,"isReplay":false,"introduction":"中文 \"引号\" 标题","screenshot":"https:\/\/example.com\/shot.jpg","gameFullName":"英雄联盟","gid":1,"startTime":1700000000,"totalCount":"5000"};
+var TT_PROFILE_INFO = {"nick":"测试主播","avatar":"https://example.com/avatar.jpg"};
+</script>
+<script>
+window.hyPlayerConfig = {
+    html5: 1,
+    vappid: 10057,
+    stream: {"data":[{"gameLiveInfo":{"nick":"测试主播","attendeeCount":12345,"avatar180":"https://example.com/avatar180.jpg"}}]},
+    iWebDefaultBitRate: 0
+};
+</script></body></html>`
+
+func TestHuyaGetStreamStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/11336", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(huyaTestPage))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// 其他直播间页面不包含内嵌数据，需要回退到移动端接口
+		w.Write([]byte(`<html><body>验证码</body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mobile := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("do") != "profileRoom" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("roomid") == "replay" {
+			w.Write([]byte(`{"status":200,"message":"","data":{"realLiveStatus":"REPLAY","liveStatus":"ON","profileInfo":{"nick":"重播主播","avatar180":"https://example.com/m.jpg"},"liveData":{"introduction":"重播 \"标题\"","gameFullName":"王者荣耀","gid":2336,"attendeeCount":"1.5\u4e07"}}}`))
+			return
+		}
+		w.Write([]byte(`{"status":422,"message":"该主播不存在！","data":""}`))
+	}))
+	defer mobile.Close()
+
+	client := NewHuyaClientWithBaseURL(server.URL, mobile.URL)
+
+	status, err := client.GetStreamStatus(context.Background(), "11336")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+	if !status.IsLive || status.Name != "测试主播" || status.Title != `中文 "引号" 标题` {
+		t.Errorf("unexpected live status: %+v", status)
+	}
+	if status.Viewers != 12345 || status.AvatarURL != "https://example.com/avatar180.jpg" || status.ThumbnailURL != "https://example.com/shot.jpg" {
+		t.Errorf("Viewers/AvatarURL/ThumbnailURL = %d/%q/%q", status.Viewers, status.AvatarURL, status.ThumbnailURL)
+	}
+	if status.Game != "英雄联盟" || status.Category.URL != "https://www.huya.com/g/1" || status.LiveSince != 1700000000 {
+		t.Errorf("Game/Category/LiveSince = %q/%+v/%d", status.Game, status.Category, status.LiveSince)
+	}
+
+	replay, err := client.GetStreamStatus(context.Background(), "replay")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() from mobile api error = %v", err)
+	}
+	if replay.State != "replay" || replay.Name != "重播主播" || replay.Title != `重播 "标题"` || replay.Viewers != 15000 || replay.ViewersText != "1.5万" {
+		t.Errorf("unexpected mobile status: %+v", replay)
+	}
+
+	if _, err := client.GetStreamStatus(context.Background(), "missing"); !errors.Is(err, ErrChannelNotFound) {
+		t.Errorf("GetStreamStatus() for missing room error = %v, want ErrChannelNotFound", err)
+	}
+}
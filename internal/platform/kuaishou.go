@@ -0,0 +1,132 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"live-channels/internal/models"
+	"regexp"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// KuaishouBaseURL 快手直播默认地址
+const KuaishouBaseURL = "https://live.kuaishou.com"
+
+// KuaishouState 快手直播间页面中 window.__INITIAL_STATE__ 的数据结构
+type KuaishouState struct {
+	Liveroom struct {
+		PlayList []struct {
+			IsLiving   bool `json:"isLiving"`
+			LiveStream struct {
+				Caption  string `json:"caption"`  // 直播标题
+				Poster   string `json:"poster"`   // 直播封面
+				CoverURL string `json:"coverUrl"` // 备用封面
+			} `json:"liveStream"`
+			Author struct {
+				ID     string `json:"id"`
+				Name   string `json:"name"`   // 主播名字
+				Avatar string `json:"avatar"` // 主播头像
+			} `json:"author"`
+			GameInfo struct {
+				Name          string `json:"name"`
+				WatchingCount string `json:"watchingCount"` // 在线观众数
+			} `json:"gameInfo"`
+			ErrorType struct {
+				Title string `json:"title"`
+			} `json:"errorType"`
+		} `json:"playList"`
+	} `json:"liveroom"`
+}
+
+func init() {
+	Register(Descriptor{
+		Name:        models.PlatformKuaishou,
+		DisplayName: "快手",
+		Icon:        "https://live.kuaishou.com/favicon.ico",
+		URLPatterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?live\.kuaishou\.com/u/([\w-]+)(?:[/?#]|$)`),
+		},
+		New: func(cfg models.PlatformConfig) StreamProvider { return NewKuaishouClientWithConfig(cfg) },
+	})
+}
+
+// KuaishouClient 快手平台客户端
+type KuaishouClient struct {
+	client  *resty.Client
+	baseURL string
+}
+
+// NewKuaishouClient 创建快手客户端
+func NewKuaishouClient() *KuaishouClient {
+	return NewKuaishouClientWithBaseURL(KuaishouBaseURL)
+}
+
+// NewKuaishouClientWithBaseURL 使用指定地址创建快手客户端（便于测试）
+func NewKuaishouClientWithBaseURL(baseURL string) *KuaishouClient {
+	return NewKuaishouClientWithConfig(models.PlatformConfig{BaseURL: baseURL})
+}
+
+// NewKuaishouClientWithConfig 使用平台配置创建快手客户端
+func NewKuaishouClientWithConfig(cfg models.PlatformConfig) *KuaishouClient {
+	return &KuaishouClient{
+		client:  newHTTPClient(cfg),
+		baseURL: baseURLOr(cfg, KuaishouBaseURL),
+	}
+}
+
+// GetStreamStatus 获取快手直播状态
+// 访问直播间页面，解析内嵌的 window.__INITIAL_STATE__ JSON
+// API: https://live.kuaishou.com/u/{id}
+func (k *KuaishouClient) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	url := fmt.Sprintf("%s/u/%s", k.baseURL, channelID)
+
+	resp, err := k.client.R().
+		SetContext(ctx).
+		SetHeader("Referer", k.baseURL+"/").
+		Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch kuaishou room page: %w: %w", ErrUpstream, err)
+	}
+	if err := statusError(resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch kuaishou room page: %w", err)
+	}
+
+	var state KuaishouState
+	if err := extractJSON(string(resp.Body()), "window.__INITIAL_STATE__=", &state); err != nil {
+		return nil, fmt.Errorf("failed to parse kuaishou initial state: %w", err)
+	}
+
+	if len(state.Liveroom.PlayList) == 0 {
+		return nil, fmt.Errorf("kuaishou room %s: %w", channelID, ErrChannelNotFound)
+	}
+
+	room := state.Liveroom.PlayList[0]
+	if room.Author.ID == "" && room.ErrorType.Title != "" {
+		return nil, fmt.Errorf("kuaishou error: %s: %w", room.ErrorType.Title, ErrBlocked)
+	}
+
+	thumbnail := room.LiveStream.Poster
+	if thumbnail == "" {
+		thumbnail = room.LiveStream.CoverURL
+	}
+	viewers, _ := parseCNNumber(room.GameInfo.WatchingCount)
+
+	status := &models.StreamStatus{
+		ChannelID:    channelID,
+		Name:         room.Author.Name,
+		Platform:     "kuaishou",
+		Title:        room.LiveStream.Caption,
+		Viewers:      int(viewers),
+		ViewersText:  room.GameInfo.WatchingCount,
+		ThumbnailURL: thumbnail,
+		AvatarURL:    room.Author.Avatar,
+		ProfileURL:   fmt.Sprintf("https://live.kuaishou.com/u/%s", channelID),
+		UpdatedAt:    time.Now().Unix(),
+	}
+	status.SetState(models.StateFromLive(room.IsLiving))
+	setCategory(status, room.GameInfo.Name, "", "")
+
+	return status, nil
+}
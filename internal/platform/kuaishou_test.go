@@ -0,0 +1,69 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const kuaishouTestPage = `<html><head><script>
+window.__INITIAL_STATE__={"liveroom":{"playList":[{"isLiving":true,"liveStream":{"caption":"测试 \"直播\" 中","poster":"https://example.com/poster.jpg","coverUrl":undefined},"author":{"id":"abc123","name":"测试主播","avatar":"https://example.com/avatar.jpg"},"gameInfo":{"name":"王者荣耀","watchingCount":"5678"}}]}};(function(){var s;(s=document.currentScript||document.scripts[document.scripts.length-1]).parentNode.removeChild(s);}());
+</script></head></html>`
+
+func TestKuaishouGetStreamStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/u/abc123" {
+			w.Write([]byte(`<script>window.__INITIAL_STATE__={"liveroom":{"playList":[]}};</script>`))
+			return
+		}
+		w.Write([]byte(kuaishouTestPage))
+	}))
+	defer server.Close()
+
+	client := NewKuaishouClientWithBaseURL(server.URL)
+	status, err := client.GetStreamStatus(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+
+	if !status.IsLive {
+		t.Errorf("IsLive = false, want true")
+	}
+	if status.Title != `测试 "直播" 中` {
+		t.Errorf("Title = %q", status.Title)
+	}
+	if status.Name != "测试主播" || status.Viewers != 5678 {
+		t.Errorf("Name/Viewers = %q/%d", status.Name, status.Viewers)
+	}
+	if status.ThumbnailURL != "https://example.com/poster.jpg" {
+		t.Errorf("ThumbnailURL = %q", status.ThumbnailURL)
+	}
+
+	if _, err := client.GetStreamStatus(context.Background(), "missing"); !errors.Is(err, ErrChannelNotFound) {
+		t.Errorf("GetStreamStatus() for missing room error = %v, want ErrChannelNotFound", err)
+	}
+}
+
+func TestKuaishouGetStreamStatusCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	client := NewKuaishouClientWithBaseURL(server.URL)
+	if _, err := client.GetStreamStatus(ctx, "abc123"); err == nil {
+		t.Fatalf("GetStreamStatus() with expired context should return error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetStreamStatus() returned after %v, want it to stop at the deadline", elapsed)
+	}
+}
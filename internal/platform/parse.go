@@ -0,0 +1,143 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// jsUndefinedRe 匹配页面脚本中 JSON 不支持的 undefined 值
+var jsUndefinedRe = regexp.MustCompile(`([:,\[])\s*undefined\s*([,}\]])`)
+
+// extractJSON 从 HTML 中提取紧跟在 marker 之后的 JSON 值并解析到 v
+// 例如 marker 为 "window.__INITIAL_STATE__=" 时，解析其后的对象直到对象结束，忽略后续脚本
+func extractJSON(html, marker string, v interface{}) error {
+	idx := strings.Index(html, marker)
+	if idx < 0 {
+		return fmt.Errorf("marker not found: %s: %w", marker, ErrParse)
+	}
+	return decodeJSONPrefix(html[idx+len(marker):], marker, v)
+}
+
+// extractJSONAssign 提取脚本中变量或属性的 JSON 值，兼容 name = {...} 和 name: {...} 两种写法及任意空白
+func extractJSONAssign(html, name string, v interface{}) error {
+	re := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\s*[=:]\s*`)
+	loc := re.FindStringIndex(html)
+	if loc == nil {
+		return fmt.Errorf("marker not found: %s: %w", name, ErrParse)
+	}
+	return decodeJSONPrefix(html[loc[1]:], name, v)
+}
+
+// decodeJSONPrefix 解析 s 开头的第一个 JSON 值，marker 仅用于错误信息
+func decodeJSONPrefix(s, marker string, v interface{}) error {
+	rest := strings.TrimSpace(s)
+	// undefined 可能连续出现（如 [undefined,undefined]），替换两次以覆盖相邻匹配
+	rest = jsUndefinedRe.ReplaceAllString(rest, "${1}null${2}")
+	rest = jsUndefinedRe.ReplaceAllString(rest, "${1}null${2}")
+
+	// json.Decoder 只读取第一个完整的 JSON 值，后续脚本内容会被忽略
+	if err := json.NewDecoder(strings.NewReader(rest)).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode json after %s: %w: %w", marker, ErrParse, err)
+	}
+
+	return nil
+}
+
+// extractField 从 HTML 中使用正则表达式提取第一个捕获组
+func extractField(html, pattern string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex pattern: %w", err)
+	}
+
+	matches := re.FindStringSubmatch(html)
+	if len(matches) < 2 {
+		return "", fmt.Errorf("field not found with pattern: %s: %w", pattern, ErrParse)
+	}
+
+	return matches[1], nil
+}
+
+// cnNumberUnits 数字文本支持的单位后缀及倍数
+var cnNumberUnits = []struct {
+	suffix string
+	scale  float64
+}{
+	{"亿", 1e8},
+	{"万", 1e4},
+	{"w", 1e4},
+	{"W", 1e4},
+}
+
+// parseCNNumber 解析平台展示用的数字文本
+// 支持千分位逗号、小数、万/亿（w）单位及末尾的 "+"，如 "12.3万"、"1.2亿"、"1,234"、"10w+"
+func parseCNNumber(s string) (int64, error) {
+	text := strings.TrimSpace(s)
+	text = strings.TrimSuffix(text, "+")
+	text = strings.ReplaceAll(text, ",", "")
+
+	scale := 1.0
+	for _, unit := range cnNumberUnits {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSuffix(text, unit.suffix)
+			scale = unit.scale
+			break
+		}
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid number %q: %w", s, ErrParse)
+	}
+	return int64(math.Round(v * scale)), nil
+}
+
+// scalarText 返回 JSON 数字或字符串的文本，字符串中的转义（如 \u4e07）会被解码，null 返回空字符串
+func scalarText(b []byte) (string, error) {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	if string(b) == "null" {
+		return "", nil
+	}
+	return string(b), nil
+}
+
+// flexInt 兼容数字和数字字符串两种格式的整数字段，字符串可带万/亿单位
+type flexInt int64
+
+// UnmarshalJSON 解析数字、数字字符串或 null，无法解析的值记为 0，不影响整体解析
+func (n *flexInt) UnmarshalJSON(b []byte) error {
+	s, err := scalarText(b)
+	if err != nil {
+		return err
+	}
+	v, _ := parseCNNumber(s)
+	*n = flexInt(v)
+	return nil
+}
+
+// flexCount 平台返回的人数字段，解析规则同 flexInt，同时保留平台的原始文本（如 "1.5万"）
+type flexCount struct {
+	Value int64
+	Text  string
+}
+
+// UnmarshalJSON 解析数字、数字字符串或 null，无法解析的值记为 0
+func (c *flexCount) UnmarshalJSON(b []byte) error {
+	s, err := scalarText(b)
+	if err != nil {
+		return err
+	}
+	c.Text = strings.TrimSpace(s)
+	c.Value, _ = parseCNNumber(s)
+	return nil
+}
@@ -1,7 +1,12 @@
 package platform
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"live-channels/internal/models"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -13,6 +18,12 @@ func TestCreateProvider(t *testing.T) {
 		{models.PlatformBilibili, false},
 		{models.PlatformDouyu, false},
 		{models.PlatformHuya, false},
+		{models.PlatformDouyin, false},
+		{models.PlatformKuaishou, false},
+		{models.PlatformCC, false},
+		{models.PlatformTwitch, false},
+		{models.PlatformYouTube, false},
+		{models.PlatformAcFun, false},
 		{models.Platform("invalid"), true},
 	}
 
@@ -25,3 +36,132 @@ func TestCreateProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestSetCategory(t *testing.T) {
+	status := &models.StreamStatus{}
+	setCategory(status, "", "网游", "https://example.com")
+	if status.Category != nil || status.Game != "" {
+		t.Errorf("setCategory() with empty name should not modify status, got %+v", status)
+	}
+
+	setCategory(status, "英雄联盟", "网游", "https://example.com/lol")
+	if status.Game != "英雄联盟" || status.Category == nil || status.Category.Parent != "网游" || status.Category.URL != "https://example.com/lol" {
+		t.Errorf("setCategory() = %+v, %+v", status, status.Category)
+	}
+
+	setCategory(status, "王者荣耀", "王者荣耀", "")
+	if status.Category.Parent != "" {
+		t.Errorf("setCategory() should drop parent equal to name, got %q", status.Category.Parent)
+	}
+}
+
+func TestParseBilibiliLiveTime(t *testing.T) {
+	if got := parseBilibiliLiveTime("2024-01-01 20:00:00"); got != 1704110400 {
+		t.Errorf("parseBilibiliLiveTime() = %d, want 1704110400", got)
+	}
+	if got := parseBilibiliLiveTime("0000-00-00 00:00:00"); got != 0 {
+		t.Errorf("parseBilibiliLiveTime() for offline = %d, want 0", got)
+	}
+}
+
+func TestParseCNNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"5678", 5678},
+		{"1,234", 1234},
+		{"12.3万", 123000},
+		{"1.2亿", 120000000},
+		{"10w+", 100000},
+		{" 3.5W ", 35000},
+		{"0", 0},
+	}
+	for _, tt := range tests {
+		got, err := parseCNNumber(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseCNNumber(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "万", "abc", "-1"} {
+		if _, err := parseCNNumber(in); !errors.Is(err, ErrParse) {
+			t.Errorf("parseCNNumber(%q) error = %v, want ErrParse", in, err)
+		}
+	}
+}
+
+func TestFlexIntUnmarshal(t *testing.T) {
+	var data struct {
+		A flexInt   `json:"a"`
+		B flexInt   `json:"b"`
+		C flexInt   `json:"c"`
+		D flexInt   `json:"d"`
+		E flexCount `json:"e"`
+		F flexCount `json:"f"`
+	}
+	body := `{"a":12,"b":"1.5\u4e07","c":"--","d":null,"e":"2.3\u4e07","f":"\u672a\u77e5"}`
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if data.A != 12 || data.B != 15000 || data.C != 0 || data.D != 0 {
+		t.Errorf("flexInt = %d/%d/%d/%d, want 12/15000/0/0", data.A, data.B, data.C, data.D)
+	}
+	if data.E.Value != 23000 || data.E.Text != "2.3万" || data.F.Value != 0 || data.F.Text != "未知" {
+		t.Errorf("flexCount = %+v/%+v", data.E, data.F)
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	endpoints := func(name string) models.PlatformConfig {
+		return models.PlatformConfig{Endpoints: map[string]string{name: "http://mirror.local/"}}
+	}
+	got := map[string]string{
+		"huya":    NewHuyaClientWithConfig(endpoints(HuyaEndpointMobile)).mobileBaseURL,
+		"acfun":   NewAcFunClientWithConfig(endpoints(AcFunEndpointID)).idBaseURL,
+		"cc":      NewCCClientWithConfig(endpoints(CCEndpointWeb)).webBaseURL,
+		"twitch":  NewTwitchClientWithConfig(endpoints(TwitchEndpointOAuth)).authBaseURL,
+		"youtube": NewYouTubeClientWithConfig(endpoints(YouTubeEndpointWeb)).webBaseURL,
+	}
+	for name, u := range got {
+		if u != "http://mirror.local" {
+			t.Errorf("%s endpoint = %q, want http://mirror.local", name, u)
+		}
+	}
+	if u := NewHuyaClientWithConfig(models.PlatformConfig{}).mobileBaseURL; u != HuyaMobileBaseURL {
+		t.Errorf("default huya mobile base URL = %q", u)
+	}
+}
+
+func TestClientWithPlatformConfig(t *testing.T) {
+	var gotUA, gotCookie, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.UserAgent()
+		gotPath = r.URL.Path
+		if cookie, err := r.Cookie("SESSDATA"); err == nil {
+			gotCookie = cookie.Value
+		}
+		w.Write([]byte(`{"code":1,"message":"test"}`))
+	}))
+	defer server.Close()
+
+	retry := 0
+	client := NewBilibiliClientWithConfig(models.PlatformConfig{
+		BaseURL:    server.URL + "/",
+		RetryCount: &retry,
+		UserAgent:  "custom-ua",
+		Cookies:    map[string]string{"SESSDATA": "secret"},
+	})
+	if client.client == GetHTTPClient() {
+		t.Errorf("client with overrides should not use the shared HTTP client")
+	}
+	client.GetStreamStatus(context.Background(), "123")
+
+	if gotUA != "custom-ua" || gotCookie != "secret" || gotPath != "/room/v1/Room/get_info" {
+		t.Errorf("request UA/cookie/path = %q/%q/%q", gotUA, gotCookie, gotPath)
+	}
+
+	if NewHuyaClient().client != GetHTTPClient() {
+		t.Errorf("client without overrides should use the shared HTTP client")
+	}
+}
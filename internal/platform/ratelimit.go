@@ -0,0 +1,154 @@
+package platform
+
+import (
+	"io"
+	"live-channels/internal/models"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 默认限流参数，按目标主机分别计算
+const (
+	DefaultRateLimit     = 5.0 // 每秒请求数
+	DefaultBurst         = 5   // 令牌桶容量
+	DefaultMaxConcurrent = 3   // 同时进行中的请求数
+)
+
+// rateLimitTransport 按目标主机限制请求速率与并发数的 RoundTripper
+// 等待期间请求的 Context 被取消时立即返回错误
+type rateLimitTransport struct {
+	next          http.RoundTripper
+	rate          float64 // 小于等于 0 时不限速
+	burst         int
+	maxConcurrent int // 小于等于 0 时不限并发
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+// hostLimiter 单个主机的令牌桶与并发信号量
+type hostLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	slots  chan struct{}
+}
+
+// newRateLimitTransport 根据平台配置包装 Transport，未配置的字段使用默认值，负数表示不限制
+func newRateLimitTransport(next http.RoundTripper, cfg models.PlatformConfig) *rateLimitTransport {
+	rate := cfg.RateLimit
+	if rate == 0 {
+		rate = DefaultRateLimit
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = DefaultBurst
+	}
+	maxConcurrent := cfg.MaxConcurrent
+	if maxConcurrent == 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
+
+	return &rateLimitTransport{
+		next:          next,
+		rate:          rate,
+		burst:         burst,
+		maxConcurrent: maxConcurrent,
+		hosts:         make(map[string]*hostLimiter),
+	}
+}
+
+// RoundTrip 等待令牌和并发名额后发送请求，响应 Body 关闭时释放并发名额
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter := t.limiter(req.URL.Host)
+	ctx := req.Context()
+
+	if t.rate > 0 {
+		if delay := limiter.reserve(t.rate, t.burst); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				limiter.cancel()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	if limiter.slots == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	select {
+	case limiter.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-limiter.slots }
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// limiter 返回指定主机的限流器，不存在时创建
+func (t *rateLimitTransport) limiter(host string) *hostLimiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limiter, ok := t.hosts[host]
+	if !ok {
+		limiter = &hostLimiter{tokens: float64(t.burst), last: time.Now()}
+		if t.maxConcurrent > 0 {
+			limiter.slots = make(chan struct{}, t.maxConcurrent)
+		}
+		t.hosts[host] = limiter
+	}
+	return limiter
+}
+
+// reserve 预占一个令牌，返回需要等待的时间
+// 令牌不足时允许透支，后续请求会排在其后等待
+func (l *hostLimiter) reserve(rate float64, burst int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * rate
+	if l.tokens > float64(burst) {
+		l.tokens = float64(burst)
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / rate * float64(time.Second))
+}
+
+// cancel 归还未使用的令牌
+func (l *hostLimiter) cancel() {
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
+
+// releaseOnClose 在 Body 关闭时释放并发名额，只释放一次
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
@@ -0,0 +1,173 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"live-channels/internal/models"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Capabilities 平台支持的可选能力
+type Capabilities struct {
+	UID   bool `json:"uid"`   // 支持通过用户 UID 查询（UIDStreamProvider）
+	Batch bool `json:"batch"` // 支持批量查询（BatchStreamProvider）
+}
+
+// Descriptor 平台描述信息，由各平台在 init() 中通过 Register 注册
+type Descriptor struct {
+	Name         models.Platform                                `json:"name"`
+	DisplayName  string                                         `json:"display_name"`
+	Icon         string                                         `json:"icon"`
+	URLPatterns  []*regexp.Regexp                               `json:"-"` // 直播间链接匹配规则，第一个捕获组为频道 ID
+	ShortLinks   []*regexp.Regexp                               `json:"-"` // 短链接匹配规则，跟随跳转后再按 URLPatterns 解析
+	ReservedIDs  []string                                       `json:"-"` // 能被 URLPatterns 匹配但不是直播间的路径，如 twitch.tv/directory，不区分大小写
+	Endpoints    []string                                       `json:"-"` // 可在配置 endpoints 中覆盖的接口地址名称
+	Options      []string                                       `json:"-"` // 可在配置 options 中填写的选项名称
+	Capabilities Capabilities                                   `json:"capabilities"`
+	New          func(cfg models.PlatformConfig) StreamProvider `json:"-"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[models.Platform]Descriptor)
+)
+
+// Register 注册直播平台，重复注册或缺少必要字段时 panic
+func Register(d Descriptor) {
+	if d.Name == "" || d.New == nil {
+		panic("platform: Register requires Name and New")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[d.Name]; dup {
+		panic(fmt.Sprintf("platform: Register called twice for %s", d.Name))
+	}
+	registry[d.Name] = d
+	models.RegisterPlatform(d.Name)
+}
+
+// Lookup 返回已注册平台的描述信息
+func Lookup(platform models.Platform) (Descriptor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	d, ok := registry[platform]
+	return d, ok
+}
+
+// Descriptors 返回所有已注册平台，按名称排序
+func Descriptors() []Descriptor {
+	registryMu.RLock()
+	descriptors := make([]Descriptor, 0, len(registry))
+	for _, d := range registry {
+		descriptors = append(descriptors, d)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(descriptors, func(i, j int) bool {
+		return descriptors[i].Name < descriptors[j].Name
+	})
+	return descriptors
+}
+
+// MatchURL 根据已注册平台的链接规则解析直播间链接，返回平台和频道 ID
+func MatchURL(rawURL string) (models.Platform, string, bool) {
+	for _, d := range Descriptors() {
+		for _, pattern := range d.URLPatterns {
+			if m := pattern.FindStringSubmatch(rawURL); len(m) > 1 && m[1] != "" && !d.isReserved(m[1]) {
+				return d.Name, m[1], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// isReserved 判断频道 ID 是否为平台保留的非直播间路径
+func (d Descriptor) isReserved(channelID string) bool {
+	for _, id := range d.ReservedIDs {
+		if strings.EqualFold(id, channelID) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxShortLinkRedirects 解析短链接时最多跟随的跳转次数
+const maxShortLinkRedirects = 10
+
+// ResolveURL 解析直播间链接，返回平台和频道 ID
+// 短链接（如 b23.tv）会先跟随跳转，直到得到可识别的直播间链接
+func ResolveURL(ctx context.Context, rawURL string) (models.Platform, string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if platform, channelID, ok := MatchURL(rawURL); ok {
+		return platform, channelID, nil
+	}
+	if !isShortLink(rawURL) {
+		return "", "", fmt.Errorf("unsupported channel url %q", rawURL)
+	}
+
+	target, err := followShortLink(ctx, newRestyClient(newTransport(nil), userAgent, 5*time.Second, 1), rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	if platform, channelID, ok := MatchURL(target); ok {
+		return platform, channelID, nil
+	}
+	return "", "", fmt.Errorf("short link %q resolved to unsupported url %q", rawURL, target)
+}
+
+// isShortLink 判断链接是否匹配某个平台的短链接规则
+func isShortLink(rawURL string) bool {
+	for _, d := range Descriptors() {
+		for _, pattern := range d.ShortLinks {
+			if pattern.MatchString(rawURL) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// followShortLink 跟随短链接跳转，返回最终地址
+// 跳转到可识别的直播间链接时立即停止，不再请求直播间页面
+func followShortLink(ctx context.Context, client *resty.Client, rawURL string) (string, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	client.SetRedirectPolicy(resty.RedirectPolicyFunc(func(req *http.Request, via []*http.Request) error {
+		if _, _, ok := MatchURL(req.URL.String()); ok {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= maxShortLinkRedirects {
+			return errors.New("too many redirects")
+		}
+		return nil
+	}))
+
+	resp, err := client.R().
+		SetContext(ctx).
+		Get(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve short link: %w: %w", ErrUpstream, err)
+	}
+
+	// 停止跳转时返回的是跳转响应本身，目标地址在 Location 中
+	requestURL := resp.RawResponse.Request.URL
+	if location := resp.Header().Get("Location"); location != "" {
+		target, err := requestURL.Parse(location)
+		if err != nil {
+			return "", fmt.Errorf("invalid redirect location %q: %w", location, ErrUpstream)
+		}
+		return target.String(), nil
+	}
+	return requestURL.String(), nil
+}
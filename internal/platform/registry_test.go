@@ -0,0 +1,152 @@
+package platform
+
+import (
+	"context"
+	"live-channels/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistryCapabilities(t *testing.T) {
+	for _, d := range Descriptors() {
+		if !d.Name.IsValid() {
+			t.Errorf("registered platform %q is not valid in models", d.Name)
+		}
+
+		provider := d.New(models.PlatformConfig{})
+		_, uid := provider.(UIDStreamProvider)
+		_, batch := provider.(BatchStreamProvider)
+		if d.Capabilities.UID != uid || d.Capabilities.Batch != batch {
+			t.Errorf("%s capabilities = %+v, provider implements uid=%v batch=%v", d.Name, d.Capabilities, uid, batch)
+		}
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register() with duplicate name should panic")
+		}
+	}()
+	Register(Descriptor{
+		Name: models.PlatformBilibili,
+		New:  func(cfg models.PlatformConfig) StreamProvider { return NewBilibiliClientWithConfig(cfg) },
+	})
+}
+
+func TestMatchURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		platform  models.Platform
+		channelID string
+		ok        bool
+	}{
+		{"https://live.bilibili.com/21013446?spm_id_from=333", models.PlatformBilibili, "21013446", true},
+		{"live.bilibili.com/h5/21013446", models.PlatformBilibili, "21013446", true},
+		{"https://www.douyu.com/9999", models.PlatformDouyu, "9999", true},
+		{"https://www.douyu.com/topic/s13?rid=9999", models.PlatformDouyu, "9999", true},
+		{"https://www.douyu.com/topic/s13?from=home&rid=9999#chat", models.PlatformDouyu, "9999", true},
+		{"https://www.huya.com/lpl", models.PlatformHuya, "lpl", true},
+		{"https://m.huya.com/211888", models.PlatformHuya, "211888", true},
+		{"https://live.douyin.com/123456789", models.PlatformDouyin, "123456789", true},
+		{"https://live.kuaishou.com/u/abc_123", models.PlatformKuaishou, "abc_123", true},
+		{"https://cc.163.com/361433/", models.PlatformCC, "361433", true},
+		{"https://www.twitch.tv/streamer", models.PlatformTwitch, "streamer", true},
+		{"https://www.youtube.com/@handle/live", models.PlatformYouTube, "@handle", true},
+		{"https://www.youtube.com/channel/UCxxxxxxxxxxxxxxxxxxxxxx", models.PlatformYouTube, "UCxxxxxxxxxxxxxxxxxxxxxx", true},
+		{"https://live.acfun.cn/live/12345", models.PlatformAcFun, "12345", true},
+		{"https://www.douyu.com/12ab", "", "", false},
+		{"https://www.douyu.com/topic/s13?rid=", "", "", false},
+		{"https://example.com/123", "", "", false},
+		{"https://www.huya.com/g/lol", "", "", false},
+		{"https://www.huya.com/l", "", "", false},
+		{"https://www.twitch.tv/videos/123456", "", "", false},
+		{"https://www.twitch.tv/directory/category/just-chatting", "", "", false},
+		{"https://www.twitch.tv/Directory", "", "", false},
+		{"https://www.twitch.tv/streamer/videos", models.PlatformTwitch, "streamer", true},
+	}
+
+	for _, tt := range tests {
+		platform, channelID, ok := MatchURL(tt.url)
+		if platform != tt.platform || channelID != tt.channelID || ok != tt.ok {
+			t.Errorf("MatchURL(%q) = %q, %q, %v; want %q, %q, %v", tt.url, platform, channelID, ok, tt.platform, tt.channelID, tt.ok)
+		}
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	platform, channelID, err := ResolveURL(context.Background(), " https://live.bilibili.com/21013446 ")
+	if err != nil || platform != models.PlatformBilibili || channelID != "21013446" {
+		t.Errorf("ResolveURL() = %q, %q, %v", platform, channelID, err)
+	}
+	if _, _, err := ResolveURL(context.Background(), "https://example.com/123"); err == nil {
+		t.Errorf("ResolveURL() with unsupported url should return error")
+	}
+}
+
+func TestFollowShortLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/abc":
+			http.Redirect(w, r, "/step", http.StatusFound)
+		case "/step":
+			// 跳转到直播间后应停止，不会真正请求 live.bilibili.com
+			http.Redirect(w, r, "https://live.bilibili.com/21013446?share_source=copy_link", http.StatusFound)
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newRestyClient(http.DefaultTransport, "test", time.Second, 0)
+	target, err := followShortLink(context.Background(), client, server.URL+"/abc")
+	if err != nil {
+		t.Fatalf("followShortLink() error = %v", err)
+	}
+	if target != "https://live.bilibili.com/21013446?share_source=copy_link" {
+		t.Errorf("followShortLink() = %q", target)
+	}
+}
+
+func TestNewProviders(t *testing.T) {
+	providers, err := NewProviders(map[models.Platform]models.PlatformConfig{
+		models.PlatformBilibili: {UserAgent: "custom-ua"},
+	})
+	if err != nil {
+		t.Fatalf("NewProviders() error = %v", err)
+	}
+	for _, d := range Descriptors() {
+		if providers[d.Name] == nil {
+			t.Errorf("NewProviders() missing provider for %s", d.Name)
+		}
+	}
+
+	if _, err := NewProviders(map[models.Platform]models.PlatformConfig{"unknown": {}}); err == nil {
+		t.Errorf("NewProviders() with unknown platform should return error")
+	}
+	if _, err := NewProviders(map[models.Platform]models.PlatformConfig{models.PlatformHuya: {Proxy: "127.0.0.1"}}); err == nil {
+		t.Errorf("NewProviders() with invalid proxy should return error")
+	}
+
+	// endpoints、options 只接受平台声明的名称，避免拼写错误被静默忽略
+	if _, err := NewProviders(map[models.Platform]models.PlatformConfig{
+		models.PlatformHuya: {Endpoints: map[string]string{"oauth": "http://mirror.local"}},
+	}); err == nil {
+		t.Errorf("NewProviders() with unknown endpoint should return error")
+	}
+	if _, err := NewProviders(map[models.Platform]models.PlatformConfig{
+		models.PlatformYouTube: {Options: map[string]string{"apikey": "key"}},
+	}); err == nil {
+		t.Errorf("NewProviders() with unknown option should return error")
+	}
+	if _, err := NewProviders(map[models.Platform]models.PlatformConfig{
+		models.PlatformTwitch: {
+			Endpoints: map[string]string{TwitchEndpointOAuth: "http://auth.local"},
+			Options:   map[string]string{TwitchOptionClientID: "id", TwitchOptionClientSecret: "secret"},
+		},
+	}); err != nil {
+		t.Errorf("NewProviders() with declared endpoints and options error = %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"live-channels/internal/models"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// TwitchAuthBaseURL Twitch OAuth 默认地址
+	TwitchAuthBaseURL = "https://id.twitch.tv"
+	// TwitchAPIBaseURL Twitch Helix API 默认地址
+	TwitchAPIBaseURL = "https://api.twitch.tv"
+
+	// TwitchEndpointOAuth OAuth 地址的 endpoints 名称，base_url 对应 Helix API 地址
+	TwitchEndpointOAuth = "oauth"
+	// TwitchOptionClientID 应用 Client ID 的 options 名称
+	TwitchOptionClientID = "client_id"
+	// TwitchOptionClientSecret 应用 Client Secret 的 options 名称
+	TwitchOptionClientSecret = "client_secret"
+)
+
+// TwitchTokenResponse App Access Token 响应
+type TwitchTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"` // 有效期（秒）
+}
+
+// TwitchUsersResponse Helix users 接口响应
+type TwitchUsersResponse struct {
+	Data []struct {
+		ID              string `json:"id"`
+		Login           string `json:"login"`
+		DisplayName     string `json:"display_name"`
+		ProfileImageURL string `json:"profile_image_url"`
+	} `json:"data"`
+}
+
+// TwitchStreamsResponse Helix streams 接口响应，未开播时 data 为空
+type TwitchStreamsResponse struct {
+	Data []struct {
+		Type         string `json:"type"` // 直播中为 "live"
+		Title        string `json:"title"`
+		GameName     string `json:"game_name"`
+		ViewerCount  int    `json:"viewer_count"`
+		ThumbnailURL string `json:"thumbnail_url"` // 含 {width}x{height} 占位符
+		StartedAt    string `json:"started_at"`    // 开播时间（RFC3339）
+	} `json:"data"`
+}
+
+func init() {
+	Register(Descriptor{
+		Name:        models.PlatformTwitch,
+		DisplayName: "Twitch",
+		Icon:        "https://www.twitch.tv/favicon.ico",
+		URLPatterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?(?:www\.|m\.)?twitch\.tv/(\w+)(?:[/?#]|$)`),
+		},
+		// 目录、录像、设置等站点页面
+		ReservedIDs: []string{
+			"directory", "videos", "settings", "subscriptions", "inventory", "wallet", "drops",
+			"search", "following", "downloads", "jobs", "p", "login", "signup", "messages",
+			"friends", "turbo", "prime", "store", "popout", "moderator",
+		},
+		Endpoints: []string{TwitchEndpointOAuth},
+		Options:   []string{TwitchOptionClientID, TwitchOptionClientSecret},
+		New:       func(cfg models.PlatformConfig) StreamProvider { return NewTwitchClientWithConfig(cfg) },
+	})
+}
+
+// TwitchClient Twitch 平台客户端
+type TwitchClient struct {
+	client       *resty.Client
+	authBaseURL  string
+	apiBaseURL   string
+	clientID     string
+	clientSecret string
+
+	mu          sync.Mutex // 保护 token 和 tokenExpiry
+	token       string     // App Access Token
+	tokenExpiry time.Time
+}
+
+// NewTwitchClient 创建 Twitch 客户端
+func NewTwitchClient() *TwitchClient {
+	return NewTwitchClientWithBaseURL(TwitchAuthBaseURL, TwitchAPIBaseURL)
+}
+
+// NewTwitchClientWithBaseURL 使用指定地址创建 Twitch 客户端（便于测试）
+func NewTwitchClientWithBaseURL(authBaseURL, apiBaseURL string) *TwitchClient {
+	return &TwitchClient{
+		client:      GetHTTPClient(),
+		authBaseURL: authBaseURL,
+		apiBaseURL:  apiBaseURL,
+	}
+}
+
+// NewTwitchClientWithConfig 使用平台配置创建 Twitch 客户端
+func NewTwitchClientWithConfig(cfg models.PlatformConfig) *TwitchClient {
+	c := NewTwitchClientWithBaseURL(endpointOr(cfg, TwitchEndpointOAuth, TwitchAuthBaseURL), baseURLOr(cfg, TwitchAPIBaseURL))
+	c.client = newHTTPClient(cfg)
+	c.clientID = cfg.Options[TwitchOptionClientID]
+	c.clientSecret = cfg.Options[TwitchOptionClientSecret]
+	return c
+}
+
+// GetStreamStatus 获取 Twitch 直播状态
+// channelID 为频道登录名，即 twitch.tv/{login}
+// API: https://api.twitch.tv/helix/users?login={login}
+// API: https://api.twitch.tv/helix/streams?user_login={login}
+func (t *TwitchClient) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	login := strings.ToLower(channelID)
+
+	var usersResp TwitchUsersResponse
+	if err := t.helixGet(ctx, "/helix/users", "login", login, &usersResp); err != nil {
+		return nil, err
+	}
+
+	if len(usersResp.Data) == 0 {
+		return nil, fmt.Errorf("twitch user %s: %w", channelID, ErrChannelNotFound)
+	}
+	user := usersResp.Data[0]
+
+	var streamsResp TwitchStreamsResponse
+	if err := t.helixGet(ctx, "/helix/streams", "user_login", login, &streamsResp); err != nil {
+		return nil, err
+	}
+
+	status := &models.StreamStatus{
+		ChannelID:  channelID,
+		Name:       user.DisplayName,
+		Platform:   "twitch",
+		State:      models.StateOffline,
+		AvatarURL:  user.ProfileImageURL,
+		ProfileURL: fmt.Sprintf("https://www.twitch.tv/%s", user.Login),
+		UpdatedAt:  time.Now().Unix(),
+	}
+
+	if len(streamsResp.Data) > 0 && streamsResp.Data[0].Type == "live" {
+		stream := streamsResp.Data[0]
+		status.SetState(models.StateLive)
+		status.Title = stream.Title
+		setCategory(status, stream.GameName, "", "https://www.twitch.tv/directory/category/"+url.PathEscape(stream.GameName))
+		status.Viewers = stream.ViewerCount
+		status.ThumbnailURL = strings.NewReplacer("{width}", "440", "{height}", "248").Replace(stream.ThumbnailURL)
+		if startedAt, err := time.Parse(time.RFC3339, stream.StartedAt); err == nil {
+			status.LiveSince = startedAt.Unix()
+		}
+	}
+
+	return status, nil
+}
+
+// helixGet 调用 Helix 接口，Token 失效时自动刷新并重试一次
+func (t *TwitchClient) helixGet(ctx context.Context, path, key, value string, result interface{}) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		clientID, token, err := t.getAppToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		resp, err := t.client.R().
+			SetContext(ctx).
+			SetQueryParam(key, value).
+			SetHeader("Client-Id", clientID).
+			SetAuthToken(token).
+			Get(t.apiBaseURL + path)
+
+		if err != nil {
+			return fmt.Errorf("failed to fetch twitch %s: %w: %w", path, ErrUpstream, err)
+		}
+
+		if resp.StatusCode() == http.StatusUnauthorized {
+			t.invalidateToken(token)
+			continue
+		}
+
+		if err := statusError(resp); err != nil {
+			return fmt.Errorf("twitch api error: %s: %w", path, err)
+		}
+
+		if err := json.Unmarshal(resp.Body(), result); err != nil {
+			return fmt.Errorf("failed to parse twitch response: %w: %w", ErrParse, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("twitch api error: unauthorized: %w", ErrUpstream)
+}
+
+// getAppToken 返回有效的 App Access Token，过期前一分钟自动重新申请
+// API: https://id.twitch.tv/oauth2/token (client_credentials)
+func (t *TwitchClient) getAppToken(ctx context.Context) (string, string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.clientID == "" || t.clientSecret == "" {
+		return "", "", fmt.Errorf("twitch client credentials: %w", ErrNotConfigured)
+	}
+
+	if t.token != "" && time.Now().Before(t.tokenExpiry) {
+		return t.clientID, t.token, nil
+	}
+
+	resp, err := t.client.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{
+			"client_id":     t.clientID,
+			"client_secret": t.clientSecret,
+			"grant_type":    "client_credentials",
+		}).
+		Post(t.authBaseURL + "/oauth2/token")
+
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch twitch app token: %w: %w", ErrUpstream, err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return "", "", fmt.Errorf("twitch auth error: %s: %w", resp.Status(), ErrUpstream)
+	}
+
+	var tokenResp TwitchTokenResponse
+	if err := json.Unmarshal(resp.Body(), &tokenResp); err != nil {
+		return "", "", fmt.Errorf("failed to parse twitch token response: %w: %w", ErrParse, err)
+	}
+
+	if tokenResp.AccessToken == "" {
+		return "", "", fmt.Errorf("twitch auth error: empty access token: %w", ErrUpstream)
+	}
+
+	t.token = tokenResp.AccessToken
+	t.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second - time.Minute)
+
+	return t.clientID, t.token, nil
+}
+
+// invalidateToken 使指定 Token 失效，避免并发请求重复清除新 Token
+func (t *TwitchClient) invalidateToken(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token == token {
+		t.token = ""
+	}
+}
@@ -0,0 +1,93 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"live-channels/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTwitchGetStreamStatus(t *testing.T) {
+	tokenRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		if r.FormValue("client_id") != "id" || r.FormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if tokenRequests == 1 {
+			w.Write([]byte(`{"access_token":"expired-token","expires_in":3600}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"valid-token","expires_in":3600}`))
+	})
+	mux.HandleFunc("/helix/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer valid-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"1","login":"streamer","display_name":"Streamer","profile_image_url":"https://example.com/avatar.png"}]}`))
+	})
+	mux.HandleFunc("/helix/streams", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Client-Id") != "id" {
+			t.Errorf("Client-Id header = %q", r.Header.Get("Client-Id"))
+		}
+		w.Write([]byte(`{"data":[{"type":"live","title":"Test Stream","game_name":"Just Chatting","viewer_count":999,"thumbnail_url":"https://example.com/live_{width}x{height}.jpg"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewTwitchClientWithConfig(models.PlatformConfig{
+		BaseURL:   server.URL,
+		Endpoints: map[string]string{TwitchEndpointOAuth: server.URL},
+		Options:   map[string]string{TwitchOptionClientID: "id", TwitchOptionClientSecret: "secret"},
+	})
+	status, err := client.GetStreamStatus(context.Background(), "Streamer")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+
+	// 第一个 Token 被拒绝后应自动刷新
+	if tokenRequests != 2 {
+		t.Errorf("token requests = %d, want 2", tokenRequests)
+	}
+	if !status.IsLive || status.Viewers != 999 || status.Game != "Just Chatting" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if status.ThumbnailURL != "https://example.com/live_440x248.jpg" {
+		t.Errorf("ThumbnailURL = %q", status.ThumbnailURL)
+	}
+	if status.ProfileURL != "https://www.twitch.tv/streamer" {
+		t.Errorf("ProfileURL = %q", status.ProfileURL)
+	}
+}
+
+func TestTwitchMissingCredentials(t *testing.T) {
+	client := NewTwitchClientWithBaseURL("http://127.0.0.1:0", "http://127.0.0.1:0")
+	if _, err := client.GetStreamStatus(context.Background(), "streamer"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("GetStreamStatus() without credentials error = %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestTwitchNetworkErrorIsUpstream(t *testing.T) {
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer auth.Close()
+	api := httptest.NewServer(http.NotFoundHandler())
+	api.Close()
+
+	retry := 0
+	client := NewTwitchClientWithConfig(models.PlatformConfig{
+		BaseURL:    api.URL,
+		Endpoints:  map[string]string{TwitchEndpointOAuth: auth.URL},
+		RetryCount: &retry,
+		Options:    map[string]string{TwitchOptionClientID: "id", TwitchOptionClientSecret: "secret"},
+	})
+	if _, err := client.GetStreamStatus(context.Background(), "streamer"); !errors.Is(err, ErrUpstream) {
+		t.Errorf("GetStreamStatus() network error = %v, want ErrUpstream", err)
+	}
+}
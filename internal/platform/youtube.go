@@ -0,0 +1,336 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"live-channels/internal/models"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// YouTubeAPIBaseURL YouTube Data API 默认地址
+	YouTubeAPIBaseURL = "https://www.googleapis.com"
+	// YouTubeWebBaseURL YouTube 网页默认地址
+	YouTubeWebBaseURL = "https://www.youtube.com"
+
+	// YouTubeEndpointWeb 网页地址的 endpoints 名称，base_url 对应 Data API 地址
+	YouTubeEndpointWeb = "web"
+	// YouTubeOptionAPIKey Data API v3 Key 的 options 名称，为空时解析直播页面
+	YouTubeOptionAPIKey = "api_key"
+)
+
+// YouTubePlayerResponse 直播页面中 ytInitialPlayerResponse 的数据结构
+type YouTubePlayerResponse struct {
+	VideoDetails struct {
+		VideoID   string `json:"videoId"`
+		Title     string `json:"title"`
+		Author    string `json:"author"`
+		ChannelID string `json:"channelId"`
+		IsLive    bool   `json:"isLive"`
+		ViewCount string `json:"viewCount"` // 直播中为同时在线人数
+	} `json:"videoDetails"`
+	Microformat struct {
+		PlayerMicroformatRenderer struct {
+			LiveBroadcastDetails struct {
+				StartTimestamp string `json:"startTimestamp"` // 开播时间（RFC3339）
+			} `json:"liveBroadcastDetails"`
+		} `json:"playerMicroformatRenderer"`
+	} `json:"microformat"`
+}
+
+// YouTubeChannelsResponse Data API channels 接口响应
+type YouTubeChannelsResponse struct {
+	Items []struct {
+		ID      string `json:"id"`
+		Snippet struct {
+			Title      string            `json:"title"`
+			Thumbnails youTubeThumbnails `json:"thumbnails"`
+		} `json:"snippet"`
+		ContentDetails struct {
+			RelatedPlaylists struct {
+				Uploads string `json:"uploads"` // 频道上传列表，直播视频也会出现在其中
+			} `json:"relatedPlaylists"`
+		} `json:"contentDetails"`
+	} `json:"items"`
+}
+
+// YouTubePlaylistItemsResponse Data API playlistItems 接口响应
+type YouTubePlaylistItemsResponse struct {
+	Items []struct {
+		ContentDetails struct {
+			VideoID string `json:"videoId"`
+		} `json:"contentDetails"`
+	} `json:"items"`
+}
+
+// YouTubeVideosResponse Data API videos 接口响应
+type YouTubeVideosResponse struct {
+	Items []struct {
+		Snippet struct {
+			Title                string            `json:"title"`
+			LiveBroadcastContent string            `json:"liveBroadcastContent"` // 直播中为 "live"
+			Thumbnails           youTubeThumbnails `json:"thumbnails"`
+		} `json:"snippet"`
+		LiveStreamingDetails struct {
+			ConcurrentViewers string `json:"concurrentViewers"`
+			ActualStartTime   string `json:"actualStartTime"` // 开播时间（RFC3339）
+		} `json:"liveStreamingDetails"`
+	} `json:"items"`
+}
+
+// youtubeRecentUploads 检查上传列表中最近的视频数量，正在直播的视频通常位于最前面
+const youtubeRecentUploads = 10
+
+// youTubeThumbnails Data API 中的缩略图集合
+type youTubeThumbnails struct {
+	Default struct {
+		URL string `json:"url"`
+	} `json:"default"`
+	High struct {
+		URL string `json:"url"`
+	} `json:"high"`
+}
+
+func init() {
+	Register(Descriptor{
+		Name:        models.PlatformYouTube,
+		DisplayName: "YouTube",
+		Icon:        "https://www.youtube.com/favicon.ico",
+		URLPatterns: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:https?://)?(?:www\.|m\.)?youtube\.com/(@[\w.-]+)(?:[/?#]|$)`),
+			regexp.MustCompile(`^(?:https?://)?(?:www\.|m\.)?youtube\.com/channel/(UC[\w-]{22})(?:[/?#]|$)`),
+		},
+		Endpoints: []string{YouTubeEndpointWeb},
+		Options:   []string{YouTubeOptionAPIKey},
+		New:       func(cfg models.PlatformConfig) StreamProvider { return NewYouTubeClientWithConfig(cfg) },
+	})
+}
+
+// YouTubeClient YouTube 平台客户端
+type YouTubeClient struct {
+	client     *resty.Client
+	apiBaseURL string
+	webBaseURL string
+	apiKey     string // Data API v3 Key，为空时通过解析直播页面获取状态
+}
+
+// NewYouTubeClient 创建 YouTube 客户端
+func NewYouTubeClient() *YouTubeClient {
+	return NewYouTubeClientWithBaseURL(YouTubeAPIBaseURL, YouTubeWebBaseURL)
+}
+
+// NewYouTubeClientWithBaseURL 使用指定地址创建 YouTube 客户端（便于测试）
+func NewYouTubeClientWithBaseURL(apiBaseURL, webBaseURL string) *YouTubeClient {
+	return &YouTubeClient{
+		client:     GetHTTPClient(),
+		apiBaseURL: apiBaseURL,
+		webBaseURL: webBaseURL,
+	}
+}
+
+// NewYouTubeClientWithConfig 使用平台配置创建 YouTube 客户端
+func NewYouTubeClientWithConfig(cfg models.PlatformConfig) *YouTubeClient {
+	c := NewYouTubeClientWithBaseURL(baseURLOr(cfg, YouTubeAPIBaseURL), endpointOr(cfg, YouTubeEndpointWeb, YouTubeWebBaseURL))
+	c.client = newHTTPClient(cfg)
+	c.apiKey = cfg.Options[YouTubeOptionAPIKey]
+	return c
+}
+
+// GetStreamStatus 获取 YouTube 直播状态
+// channelID 为频道 ID（UC 开头）或 @handle
+// 配置了 API Key 时使用 Data API v3，否则解析 /live 页面的 ytInitialPlayerResponse
+func (y *YouTubeClient) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	if y.apiKey != "" {
+		return y.getStatusFromAPI(ctx, channelID, y.apiKey)
+	}
+	return y.getStatusFromPage(ctx, channelID)
+}
+
+// getStatusFromPage 解析频道 /live 页面获取直播状态
+// 开播时 /live 为直播视频页，未开播时为频道主页，频道名与头像从 og 标签获取
+// API: https://www.youtube.com/channel/{id}/live 或 https://www.youtube.com/@{handle}/live
+func (y *YouTubeClient) getStatusFromPage(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	path := youtubeChannelPath(channelID)
+
+	resp, err := y.client.R().
+		SetContext(ctx).
+		SetCookie(&http.Cookie{Name: "CONSENT", Value: "YES+cb"}).
+		SetHeader("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8").
+		Get(y.webBaseURL + path + "/live")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch youtube live page: %w: %w", ErrUpstream, err)
+	}
+
+	if err := statusError(resp); err != nil {
+		return nil, fmt.Errorf("youtube channel %s: %w", channelID, err)
+	}
+
+	body := string(resp.Body())
+	status := &models.StreamStatus{
+		ChannelID:  channelID,
+		Name:       channelID,
+		Platform:   "youtube",
+		State:      models.StateOffline,
+		ProfileURL: "https://www.youtube.com" + path,
+		UpdatedAt:  time.Now().Unix(),
+	}
+
+	var player YouTubePlayerResponse
+	if err := extractJSON(body, "ytInitialPlayerResponse = ", &player); err == nil && player.VideoDetails.IsLive {
+		details := player.VideoDetails
+		status.SetState(models.StateLive)
+		status.Name = details.Author
+		status.Title = details.Title
+		status.Viewers, _ = strconv.Atoi(details.ViewCount)
+		status.ThumbnailURL = fmt.Sprintf("https://i.ytimg.com/vi/%s/hqdefault_live.jpg", details.VideoID)
+		startTimestamp := player.Microformat.PlayerMicroformatRenderer.LiveBroadcastDetails.StartTimestamp
+		if startedAt, err := time.Parse(time.RFC3339, startTimestamp); err == nil {
+			status.LiveSince = startedAt.Unix()
+		}
+		return status, nil
+	}
+
+	// 未开播：页面为频道主页
+	if name, err := extractField(body, `<meta property="og:title" content="([^"]*)"`); err == nil {
+		status.Name = html.UnescapeString(name)
+	}
+	if avatar, err := extractField(body, `<meta property="og:image" content="([^"]*)"`); err == nil {
+		status.AvatarURL = html.UnescapeString(avatar)
+	}
+
+	return status, nil
+}
+
+// getStatusFromAPI 通过 Data API v3 获取直播状态
+// 不使用 search 接口（每次 100 配额），而是读取频道上传列表中最近的视频，再用 videos 接口判断是否正在直播
+// 三个接口各消耗 1 配额，每次查询共 3 配额
+// API: https://www.googleapis.com/youtube/v3/channels?part=snippet,contentDetails
+// API: https://www.googleapis.com/youtube/v3/playlistItems?playlistId={uploads}
+// API: https://www.googleapis.com/youtube/v3/videos?part=snippet,liveStreamingDetails
+func (y *YouTubeClient) getStatusFromAPI(ctx context.Context, channelID, key string) (*models.StreamStatus, error) {
+	channelParams := map[string]string{"part": "snippet,contentDetails", "key": key}
+	if isYouTubeChannelID(channelID) {
+		channelParams["id"] = channelID
+	} else {
+		channelParams["forHandle"] = "@" + strings.TrimPrefix(channelID, "@")
+	}
+
+	var channelsResp YouTubeChannelsResponse
+	if err := y.apiGet(ctx, "/youtube/v3/channels", channelParams, &channelsResp); err != nil {
+		return nil, err
+	}
+
+	if len(channelsResp.Items) == 0 {
+		return nil, fmt.Errorf("youtube channel %s: %w", channelID, ErrChannelNotFound)
+	}
+	channel := channelsResp.Items[0]
+
+	status := &models.StreamStatus{
+		ChannelID:  channelID,
+		Name:       channel.Snippet.Title,
+		Platform:   "youtube",
+		State:      models.StateOffline,
+		AvatarURL:  channel.Snippet.Thumbnails.Default.URL,
+		ProfileURL: "https://www.youtube.com" + youtubeChannelPath(channelID),
+		UpdatedAt:  time.Now().Unix(),
+	}
+
+	uploads := channel.ContentDetails.RelatedPlaylists.Uploads
+	if uploads == "" {
+		return status, nil
+	}
+
+	var playlistResp YouTubePlaylistItemsResponse
+	if err := y.apiGet(ctx, "/youtube/v3/playlistItems", map[string]string{
+		"part":       "contentDetails",
+		"playlistId": uploads,
+		"maxResults": strconv.Itoa(youtubeRecentUploads),
+		"key":        key,
+	}, &playlistResp); err != nil {
+		// 没有上传过视频的频道上传列表不存在
+		if errors.Is(err, ErrChannelNotFound) {
+			return status, nil
+		}
+		return nil, err
+	}
+
+	videoIDs := make([]string, 0, len(playlistResp.Items))
+	for _, item := range playlistResp.Items {
+		if item.ContentDetails.VideoID != "" {
+			videoIDs = append(videoIDs, item.ContentDetails.VideoID)
+		}
+	}
+	if len(videoIDs) == 0 {
+		return status, nil
+	}
+
+	var videosResp YouTubeVideosResponse
+	if err := y.apiGet(ctx, "/youtube/v3/videos", map[string]string{
+		"part": "snippet,liveStreamingDetails",
+		"id":   strings.Join(videoIDs, ","),
+		"key":  key,
+	}, &videosResp); err != nil {
+		return nil, err
+	}
+
+	for _, video := range videosResp.Items {
+		if video.Snippet.LiveBroadcastContent != "live" {
+			continue
+		}
+		status.SetState(models.StateLive)
+		status.Title = video.Snippet.Title
+		status.Viewers, _ = strconv.Atoi(video.LiveStreamingDetails.ConcurrentViewers)
+		status.ThumbnailURL = video.Snippet.Thumbnails.High.URL
+		if startedAt, err := time.Parse(time.RFC3339, video.LiveStreamingDetails.ActualStartTime); err == nil {
+			status.LiveSince = startedAt.Unix()
+		}
+		break
+	}
+
+	return status, nil
+}
+
+// apiGet 调用 Data API 并解析响应
+func (y *YouTubeClient) apiGet(ctx context.Context, path string, params map[string]string, result interface{}) error {
+	resp, err := y.client.R().
+		SetContext(ctx).
+		SetQueryParams(params).
+		Get(y.apiBaseURL + path)
+
+	if err != nil {
+		return fmt.Errorf("failed to fetch youtube %s: %w: %w", path, ErrUpstream, err)
+	}
+
+	if err := statusError(resp); err != nil {
+		return fmt.Errorf("youtube api error: %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(resp.Body(), result); err != nil {
+		return fmt.Errorf("failed to parse youtube response: %w: %w", ErrParse, err)
+	}
+
+	return nil
+}
+
+// youtubeChannelPath 根据频道 ID 或 handle 生成频道路径
+func youtubeChannelPath(channelID string) string {
+	if isYouTubeChannelID(channelID) {
+		return "/channel/" + channelID
+	}
+	return "/@" + strings.TrimPrefix(channelID, "@")
+}
+
+// isYouTubeChannelID 判断是否为频道 ID（UC 开头共 24 位），否则视为 handle
+func isYouTubeChannelID(channelID string) bool {
+	return len(channelID) == 24 && strings.HasPrefix(channelID, "UC")
+}
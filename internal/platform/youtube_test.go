@@ -0,0 +1,115 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"live-channels/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const youtubeLivePage = `<html><head><meta property="og:title" content="Live Video"></head><body><script>
+var ytInitialPlayerResponse = {"videoDetails":{"videoId":"abc123","title":"测试直播 \"YouTube\"","author":"Test Channel","channelId":"UCxxxxxxxxxxxxxxxxxxxxxx","isLive":true,"viewCount":"2048"}};var meta = document.createElement('meta');
+</script></body></html>`
+
+const youtubeOfflinePage = `<html><head><meta property="og:title" content="Test &amp; Channel"><meta property="og:image" content="https://example.com/avatar.jpg"></head><body></body></html>`
+
+func TestYouTubeGetStreamStatusFromPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/@live/live":
+			w.Write([]byte(youtubeLivePage))
+		case "/channel/UCxxxxxxxxxxxxxxxxxxxxxx/live":
+			w.Write([]byte(youtubeOfflinePage))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewYouTubeClientWithBaseURL(server.URL, server.URL)
+
+	status, err := client.GetStreamStatus(context.Background(), "@live")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+	if !status.IsLive || status.Viewers != 2048 || status.Name != "Test Channel" {
+		t.Errorf("unexpected live status: %+v", status)
+	}
+	if status.Title != `测试直播 "YouTube"` {
+		t.Errorf("Title = %q", status.Title)
+	}
+
+	offline, err := client.GetStreamStatus(context.Background(), "UCxxxxxxxxxxxxxxxxxxxxxx")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() offline error = %v", err)
+	}
+	if offline.IsLive || offline.Name != "Test & Channel" || offline.AvatarURL != "https://example.com/avatar.jpg" {
+		t.Errorf("unexpected offline status: %+v", offline)
+	}
+
+	if _, err := client.GetStreamStatus(context.Background(), "@missing"); err == nil {
+		t.Errorf("GetStreamStatus() for missing channel should return error")
+	}
+}
+
+func TestYouTubeGetStreamStatusFromAPI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/youtube/v3/channels", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "test-key" || r.URL.Query().Get("forHandle") != "@handle" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"items":[{"id":"UCyyyyyyyyyyyyyyyyyyyyyy","snippet":{"title":"API Channel","thumbnails":{"default":{"url":"https://example.com/a.jpg"}}},"contentDetails":{"relatedPlaylists":{"uploads":"UUyyyyyyyyyyyyyyyyyyyyyy"}}}]}`))
+	})
+	mux.HandleFunc("/youtube/v3/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("playlistId") != "UUyyyyyyyyyyyyyyyyyyyyyy" {
+			t.Errorf("playlistId = %q", r.URL.Query().Get("playlistId"))
+		}
+		w.Write([]byte(`{"items":[{"contentDetails":{"videoId":"vid1"}},{"contentDetails":{"videoId":"vid2"}}]}`))
+	})
+	mux.HandleFunc("/youtube/v3/videos", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") != "vid1,vid2" {
+			t.Errorf("videos id = %q, want vid1,vid2", r.URL.Query().Get("id"))
+		}
+		w.Write([]byte(`{"items":[{"snippet":{"title":"Old Upload","liveBroadcastContent":"none"}},{"snippet":{"title":"API Live","liveBroadcastContent":"live","thumbnails":{"high":{"url":"https://example.com/t.jpg"}}},"liveStreamingDetails":{"concurrentViewers":"321"}}]}`))
+	})
+	// search 接口每次消耗 100 配额，不应被调用
+	mux.HandleFunc("/youtube/v3/search", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("search endpoint must not be used")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewYouTubeClientWithConfig(models.PlatformConfig{
+		BaseURL:   server.URL,
+		Endpoints: map[string]string{YouTubeEndpointWeb: server.URL},
+		Options:   map[string]string{YouTubeOptionAPIKey: "test-key"},
+	})
+	status, err := client.GetStreamStatus(context.Background(), "handle")
+	if err != nil {
+		t.Fatalf("GetStreamStatus() error = %v", err)
+	}
+	if !status.IsLive || status.Title != "API Live" || status.Viewers != 321 || status.Name != "API Channel" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if status.ProfileURL != "https://www.youtube.com/@handle" {
+		t.Errorf("ProfileURL = %q", status.ProfileURL)
+	}
+}
+
+func TestYouTubeAPINetworkErrorIsUpstream(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	retry := 0
+	client := NewYouTubeClientWithConfig(models.PlatformConfig{
+		BaseURL:    server.URL,
+		RetryCount: &retry,
+		Options:    map[string]string{YouTubeOptionAPIKey: "test-key"},
+	})
+	if _, err := client.GetStreamStatus(context.Background(), "handle"); !errors.Is(err, ErrUpstream) {
+		t.Errorf("GetStreamStatus() network error = %v, want ErrUpstream", err)
+	}
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"live-channels/internal/logger"
+	"live-channels/internal/models"
+	"live-channels/internal/platform"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 默认轮询参数
+const (
+	DefaultPollInterval = 60 * time.Second // 频道轮询间隔
+	pollTick            = time.Second      // 检查到期频道的周期
+	firstPollSpread     = 10 * time.Second // 启动时不支持批量查询的频道的首次轮询分布范围
+
+	// 自适应轮询
+	DefaultFastInterval = 30 * time.Second // 直播中或临近常规开播时间的轮询间隔
+	DefaultSlowInterval = 10 * time.Minute // 长期未开播时退避到的最长间隔
+	offlineBackoffStep  = time.Hour        // 未开播每满一个周期，轮询间隔翻倍
+	goLiveLead          = 30 * time.Minute // 提前多久进入常规开播时段
+	goLiveHourThreshold = 2                // 某个小时内开播达到该次数才视为常规开播时段
+)
+
+// pollState 单个频道的轮询状态
+type pollState struct {
+	next         time.Time
+	observed     bool      // 是否已获取到有效状态
+	live         bool      // 最近一次是否在直播
+	offlineSince time.Time // 观察到下播（或首次观察到未开播）的时间
+	sessionStart int64     // 最近一次直播的开播时间，用于避免重复统计同一场直播
+	goLiveHours  [24]int   // 按本地时间小时统计的开播次数
+}
+
+// observe 根据最新获取的状态更新直播历史，获取失败或旧缓存不更新
+// 返回是否统计了一场新的直播
+func (p *pollState) observe(status *models.StreamStatus, now time.Time) bool {
+	if status == nil || status.Error != nil || status.Stale {
+		return false
+	}
+	counted := false
+
+	live := status.State == models.StateLive
+	if live {
+		start := status.LiveSince
+		if start <= 0 {
+			start = now.Unix()
+		}
+		// 首次观察到开播，或平台给出的开播时间变化（下播后重新开播）时计入统计
+		if !p.live && start != p.sessionStart {
+			p.goLiveHours[time.Unix(start, 0).In(now.Location()).Hour()]++
+			p.sessionStart = start
+			counted = true
+		}
+	} else if p.live || !p.observed {
+		p.offlineSince = now
+	}
+	p.observed = true
+	p.live = live
+	return counted
+}
+
+// nearGoLiveHour 返回当前是否处于常规开播时段（含开播前 goLiveLead）
+func (p *pollState) nearGoLiveHour(now time.Time) bool {
+	return p.goLiveHours[now.Hour()] >= goLiveHourThreshold ||
+		p.goLiveHours[now.Add(goLiveLead).Hour()] >= goLiveHourThreshold
+}
+
+// StartPolling 启动后台轮询，按各频道的间隔定期刷新直播状态，ctx 取消后停止
+// 启动后 GetAllStreamStatus 等接口只读取内存中的结果，不再请求上游
+func (s *StreamService) StartPolling(ctx context.Context) {
+	s.loadGoLiveHistory()
+	s.polling.Store(true)
+	go func() {
+		defer s.polling.Store(false)
+
+		ticker := time.NewTicker(pollTick)
+		defer ticker.Stop()
+		for {
+			s.pollDue(ctx, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// pollDue 刷新到达轮询时间的频道并安排下次轮询，返回本次刷新的频道数
+func (s *StreamService) pollDue(ctx context.Context, now time.Time) int {
+	var due []models.ChannelConfig
+	for _, ch := range s.config.Channels {
+		state, ok := s.pollStates[pollKey(ch)]
+		if !ok {
+			state = &pollState{
+				next:        s.firstPoll(ch, now),
+				goLiveHours: s.goLiveHistory[pollKey(ch)],
+			}
+			s.pollStates[pollKey(ch)] = state
+		}
+		if !state.next.After(now) {
+			due = append(due, ch)
+		}
+	}
+	if len(due) == 0 {
+		return 0
+	}
+
+	logger.Debug("Polling channels", zap.Int("channels", len(due)))
+	s.refreshChannels(ctx, due)
+
+	counted := false
+	for _, ch := range due {
+		state := s.pollStates[pollKey(ch)]
+		s.cacheMu.RLock()
+		if state.observe(s.latest[pollKey(ch)], now) {
+			counted = true
+		}
+		s.cacheMu.RUnlock()
+		state.next = s.nextPoll(ch, state, now)
+	}
+	if counted {
+		s.saveGoLiveHistory()
+	}
+	return len(due)
+}
+
+// loadGoLiveHistory 读取保存的开播时段统计，使重启后仍能识别常规开播时段
+// 未开启自适应轮询或未配置保存路径时跳过，文件不存在时从零开始统计
+func (s *StreamService) loadGoLiveHistory() {
+	path := s.config.Polling.StateFile
+	if !s.config.Polling.Adaptive || path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Failed to read polling state", zap.String("path", path), zap.Error(err))
+		}
+		return
+	}
+
+	var history map[string][24]int
+	if err := json.Unmarshal(data, &history); err != nil {
+		logger.Warn("Failed to parse polling state", zap.String("path", path), zap.Error(err))
+		return
+	}
+	s.goLiveHistory = history
+}
+
+// saveGoLiveHistory 保存各频道的开播时段统计
+// 已不在配置中的频道保留原有统计；先写临时文件再重命名，避免写入中断导致文件损坏
+func (s *StreamService) saveGoLiveHistory() {
+	path := s.config.Polling.StateFile
+	if !s.config.Polling.Adaptive || path == "" {
+		return
+	}
+
+	if s.goLiveHistory == nil {
+		s.goLiveHistory = make(map[string][24]int)
+	}
+	for key, state := range s.pollStates {
+		if state.goLiveHours != ([24]int{}) {
+			s.goLiveHistory[key] = state.goLiveHours
+		}
+	}
+
+	data, err := json.MarshalIndent(s.goLiveHistory, "", "  ")
+	if err == nil {
+		tmp := path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0644); err == nil {
+			err = os.Rename(tmp, path)
+		}
+	}
+	if err != nil {
+		logger.Warn("Failed to save polling state", zap.String("path", path), zap.Error(err))
+	}
+}
+
+// refreshChannels 强制刷新频道列表并等待全部完成
+// 与 fetchStreamStatuses 不同，每个频道单独计算 DefaultFetchTimeout，排队等待限流的频道不会因整体超时而失败
+func (s *StreamService) refreshChannels(ctx context.Context, channels []models.ChannelConfig) {
+	freshAfter := time.Now()
+
+	batchCtx, cancel := context.WithTimeout(ctx, DefaultFetchTimeout)
+	s.prefetchBatch(batchCtx, channels, freshAfter)
+	cancel()
+
+	jobs := make(chan models.ChannelConfig, len(channels))
+	for _, ch := range channels {
+		jobs <- ch
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(DefaultWorkerCount, len(channels)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ch := range jobs {
+				chCtx, cancel := context.WithTimeout(ctx, DefaultFetchTimeout)
+				s.storeLatest(ch, s.fetchChannel(chCtx, ch, freshAfter))
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// baseInterval 返回频道配置的轮询间隔，频道配置优先于全局配置
+func (s *StreamService) baseInterval(ch models.ChannelConfig) time.Duration {
+	if ch.Interval > 0 {
+		return time.Duration(ch.Interval) * time.Second
+	}
+	if s.config.Polling.Interval > 0 {
+		return time.Duration(s.config.Polling.Interval) * time.Second
+	}
+	return DefaultPollInterval
+}
+
+// isBatchPlatform 返回平台是否支持批量查询
+func (s *StreamService) isBatchPlatform(platformType models.Platform) bool {
+	_, ok := s.providers[platformType].(platform.BatchStreamProvider)
+	return ok
+}
+
+// firstPoll 返回频道的首次轮询时间
+// 支持批量查询的平台立即轮询，所有频道合并为一次批量请求；
+// 其他频道在 firstPollSpread 内随机分布，避免启动时同时请求上游
+func (s *StreamService) firstPoll(ch models.ChannelConfig, now time.Time) time.Time {
+	if s.isBatchPlatform(ch.Platform) {
+		return now
+	}
+	spread := min(s.baseInterval(ch), firstPollSpread)
+	return now.Add(time.Duration(rand.Int64N(int64(spread))))
+}
+
+// nextPoll 返回频道的下次轮询时间
+// 支持批量查询的平台不加抖动，下次轮询时间对齐到间隔的整数倍，同一平台间隔相同（或成倍数）的频道
+// 在同一轮中到期并合并为一次批量请求；其他频道叠加随机抖动错开请求
+func (s *StreamService) nextPoll(ch models.ChannelConfig, state *pollState, now time.Time) time.Time {
+	if !s.isBatchPlatform(ch.Platform) {
+		return now.Add(s.pollInterval(ch, state, now))
+	}
+	interval := max(s.channelInterval(ch, state, now), pollTick)
+	next := now.Truncate(interval).Add(interval)
+	// 距离下一个对齐时间过近时顺延一个间隔，避免刚轮询完又立即轮询
+	if next.Sub(now) < interval/2 {
+		next = next.Add(interval)
+	}
+	return next
+}
+
+// channelInterval 返回频道不含抖动的轮询间隔
+// 开启自适应轮询且频道未单独配置间隔时，根据直播状态和开播历史调整
+func (s *StreamService) channelInterval(ch models.ChannelConfig, state *pollState, now time.Time) time.Duration {
+	interval := s.baseInterval(ch)
+	if ch.Interval <= 0 && s.config.Polling.Adaptive && state != nil {
+		interval = s.adaptiveInterval(interval, state, now)
+	}
+	return interval
+}
+
+// pollInterval 返回频道的轮询间隔，并叠加随机抖动避免请求集中
+func (s *StreamService) pollInterval(ch models.ChannelConfig, state *pollState, now time.Time) time.Duration {
+	interval := s.channelInterval(ch, state, now)
+
+	jitter := interval / 10
+	if s.config.Polling.Jitter > 0 {
+		jitter = time.Duration(s.config.Polling.Jitter) * time.Second
+	} else if s.config.Polling.Jitter < 0 {
+		jitter = 0
+	}
+	if jitter > 0 {
+		interval += time.Duration(rand.Int64N(int64(2*jitter+1))) - jitter
+	}
+	if interval < pollTick {
+		interval = pollTick
+	}
+	return interval
+}
+
+// adaptiveInterval 直播中或临近常规开播时段使用快速间隔；未开播时从基础间隔开始，
+// 每满 offlineBackoffStep 翻倍，直到慢速间隔
+func (s *StreamService) adaptiveInterval(base time.Duration, state *pollState, now time.Time) time.Duration {
+	fast := DefaultFastInterval
+	if s.config.Polling.FastInterval > 0 {
+		fast = time.Duration(s.config.Polling.FastInterval) * time.Second
+	}
+	slow := DefaultSlowInterval
+	if s.config.Polling.SlowInterval > 0 {
+		slow = time.Duration(s.config.Polling.SlowInterval) * time.Second
+	}
+
+	if state.live || state.nearGoLiveHour(now) {
+		return fast
+	}
+	if !state.observed {
+		return base
+	}
+
+	interval := base
+	for steps := now.Sub(state.offlineSince) / offlineBackoffStep; steps > 0 && interval < slow; steps-- {
+		interval *= 2
+	}
+	return min(interval, max(slow, base))
+}
+
+// storeLatest 保存频道最近一次获取的结果，并发布与上次结果相比产生的事件
+func (s *StreamService) storeLatest(ch models.ChannelConfig, status *models.StreamStatus) {
+	if status == nil {
+		return
+	}
+	copiedStatus := *status
+	s.cacheMu.Lock()
+	s.latest[pollKey(ch)] = &copiedStatus
+	s.cacheMu.Unlock()
+
+	for _, event := range s.detector.Observe(ch, copiedStatus, time.Now()) {
+		s.bus.Publish(event)
+	}
+}
+
+// snapshot 返回内存中各频道最近一次的结果，尚未完成首次轮询的频道返回 pending 条目
+func (s *StreamService) snapshot(channels []models.ChannelConfig) []models.StreamStatus {
+	statuses := make([]models.StreamStatus, 0, len(channels))
+	s.cacheMu.RLock()
+	for _, ch := range channels {
+		if status, ok := s.latest[pollKey(ch)]; ok {
+			copiedStatus := *status
+			s.applyConfigOverrides(&copiedStatus, ch)
+			statuses = append(statuses, copiedStatus)
+		} else {
+			statuses = append(statuses, *s.errorStatus(ch, "pending", "waiting for the first poll"))
+		}
+	}
+	s.cacheMu.RUnlock()
+
+	s.sortStreamStatus(statuses)
+	return statuses
+}
+
+// pollKey 返回频道在轮询状态中的键
+func pollKey(ch models.ChannelConfig) string {
+	return string(ch.Platform) + ":" + ch.Key()
+}
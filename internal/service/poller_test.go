@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"live-channels/internal/events"
+	"live-channels/internal/models"
+	"live-channels/internal/platform"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProvider 测试用 Provider，记录调用次数
+type countingProvider struct {
+	calls atomic.Int32
+}
+
+func (f *countingProvider) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	f.calls.Add(1)
+	return &models.StreamStatus{ChannelID: channelID, State: models.StateLive, IsLive: true}, nil
+}
+
+// dueNow 初始化所有频道的轮询状态并使其立即到期，跳过首次轮询时间的随机分布
+func dueNow(s *StreamService, now time.Time) {
+	for _, ch := range s.config.Channels {
+		s.pollStates[pollKey(ch)] = &pollState{next: now}
+	}
+}
+
+func TestPollDue(t *testing.T) {
+	provider := &countingProvider{}
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{
+			{Platform: models.PlatformDouyu, ChannelID: "1"},
+			{Platform: models.PlatformDouyu, ChannelID: "2", Interval: 300},
+		},
+		Polling: models.PollingConfig{Interval: 30, Jitter: -1},
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{models.PlatformDouyu: provider})
+	ctx := context.Background()
+	now := time.Now()
+	dueNow(service, now)
+
+	if n := service.pollDue(ctx, now); n != 2 {
+		t.Fatalf("first pollDue() = %d, want 2", n)
+	}
+	if n := service.pollDue(ctx, now.Add(10*time.Second)); n != 0 {
+		t.Errorf("pollDue() before interval = %d, want 0", n)
+	}
+	if n := service.pollDue(ctx, now.Add(30*time.Second)); n != 1 {
+		t.Errorf("pollDue() after global interval = %d, want 1", n)
+	}
+	if n := service.pollDue(ctx, now.Add(300*time.Second)); n != 2 {
+		t.Errorf("pollDue() after channel interval = %d, want 2", n)
+	}
+	if got := provider.calls.Load(); got != 5 {
+		t.Errorf("provider calls = %d, want 5", got)
+	}
+}
+
+func TestPollingServesFromMemory(t *testing.T) {
+	provider := &countingProvider{}
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{
+			{Platform: models.PlatformDouyu, ChannelID: "1", Name: "配置名称"},
+			{Platform: models.PlatformDouyu, ChannelID: "2"},
+		},
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{models.PlatformDouyu: provider})
+	service.polling.Store(true)
+	ctx := context.Background()
+
+	// 首次轮询前返回 pending 条目，不请求上游
+	statuses, _ := service.GetAllStreamStatus(ctx, 0)
+	if len(statuses) != 2 || statuses[0].Error == nil || statuses[0].Error.Kind != "pending" {
+		t.Fatalf("statuses before first poll = %+v", statuses)
+	}
+
+	dueNow(service, time.Now())
+	service.pollDue(ctx, time.Now())
+	statuses, _ = service.GetAllStreamStatus(ctx, 0)
+	if len(statuses) != 2 || statuses[0].Error != nil || !statuses[0].IsLive {
+		t.Fatalf("statuses after poll = %+v", statuses)
+	}
+	if statuses[0].Name != "配置名称" && statuses[1].Name != "配置名称" {
+		t.Errorf("config name override not applied: %+v", statuses)
+	}
+	if got := provider.calls.Load(); got != 2 {
+		t.Errorf("provider calls = %d, want 2 (handlers must not fetch)", got)
+	}
+}
+
+func TestFirstPollSpread(t *testing.T) {
+	provider := &countingProvider{}
+	cfg := &models.Config{Polling: models.PollingConfig{Interval: 60}}
+	for i := 0; i < 50; i++ {
+		cfg.Channels = append(cfg.Channels, models.ChannelConfig{Platform: models.PlatformDouyu, ChannelID: fmt.Sprint(i)})
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{models.PlatformDouyu: provider})
+	now := time.Now()
+
+	// 首次轮询时间分布在 firstPollSpread 内，启动时不会同时请求所有频道
+	if n := service.pollDue(context.Background(), now); n > 15 {
+		t.Errorf("pollDue() at startup = %d, want channels spread across %v", n, firstPollSpread)
+	}
+	for _, ch := range cfg.Channels {
+		next := service.pollStates[pollKey(ch)].next
+		if next.Before(now) || next.After(now.Add(firstPollSpread)) {
+			t.Fatalf("first poll of %s at %v, want within %v of %v", ch.ChannelID, next, firstPollSpread, now)
+		}
+	}
+	if n := service.pollDue(context.Background(), now.Add(firstPollSpread)); n != 50 {
+		t.Errorf("pollDue() after the spread window = %d, want 50", n)
+	}
+}
+
+func TestBatchChannelsPolledTogether(t *testing.T) {
+	provider := &batchProvider{}
+	cfg := &models.Config{Polling: models.PollingConfig{Interval: 60}}
+	for i := 0; i < 80; i++ {
+		cfg.Channels = append(cfg.Channels, models.ChannelConfig{Platform: models.PlatformBilibili, ChannelID: fmt.Sprint(i)})
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{models.PlatformBilibili: provider})
+
+	// 启动时立即轮询；之后 5 分钟内每分钟一次批量请求，不会因抖动拆成多次小批量或单独请求
+	now := time.Now()
+	if n := service.pollDue(context.Background(), now); n != 80 {
+		t.Fatalf("pollDue() at startup = %d, want 80", n)
+	}
+	for tick := pollTick; tick <= 5*time.Minute; tick += pollTick {
+		service.pollDue(context.Background(), now.Add(tick))
+	}
+	if batch, single := provider.batchCalls.Load(), provider.singleCalls.Load(); batch > 6 || single != 0 {
+		t.Errorf("batch=%d single=%d over 5 minutes, want at most 6 batch calls and no single calls", batch, single)
+	}
+}
+
+// batchProvider 测试用 Provider，支持批量查询并分别记录批量和单独查询的次数
+type batchProvider struct {
+	batchCalls  atomic.Int32
+	singleCalls atomic.Int32
+}
+
+func (f *batchProvider) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	f.singleCalls.Add(1)
+	return &models.StreamStatus{ChannelID: channelID}, nil
+}
+
+func (f *batchProvider) GetStreamStatuses(ctx context.Context, channels []models.ChannelConfig) (map[string]*models.StreamStatus, error) {
+	f.batchCalls.Add(1)
+	statuses := make(map[string]*models.StreamStatus, len(channels))
+	for _, ch := range channels {
+		statuses[ch.Key()] = &models.StreamStatus{ChannelID: ch.ChannelID, State: models.StateLive, IsLive: true}
+	}
+	return statuses, nil
+}
+
+func TestPollingUsesBatchResults(t *testing.T) {
+	provider := &batchProvider{}
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{
+			{Platform: models.PlatformBilibili, ChannelID: "1"},
+			{Platform: models.PlatformBilibili, ChannelID: "2"},
+			{Platform: models.PlatformBilibili, ChannelID: "3"},
+		},
+		Polling: models.PollingConfig{Interval: 60, Jitter: -1},
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{models.PlatformBilibili: provider})
+	ctx := context.Background()
+	now := time.Now()
+	dueNow(service, now)
+
+	service.pollDue(ctx, now)
+	if batch, single := provider.batchCalls.Load(), provider.singleCalls.Load(); batch != 1 || single != 0 {
+		t.Fatalf("first poll: batch=%d single=%d, want batch=1 single=0", batch, single)
+	}
+
+	// 下一轮轮询（对齐到间隔整数倍，最迟 1.5 个间隔后）仍然使用批量查询，而不是命中上一轮的缓存或逐个请求
+	service.pollDue(ctx, now.Add(90*time.Second))
+	if batch, single := provider.batchCalls.Load(), provider.singleCalls.Load(); batch != 2 || single != 0 {
+		t.Fatalf("second poll: batch=%d single=%d, want batch=2 single=0", batch, single)
+	}
+
+	service.polling.Store(true)
+	statuses, _ := service.GetAllStreamStatus(ctx, 0)
+	if len(statuses) != 3 || !statuses[0].IsLive {
+		t.Errorf("statuses = %+v", statuses)
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	service := NewStreamService(&models.Config{Polling: models.PollingConfig{Interval: 100, Jitter: 5}}, nil)
+	for i := 0; i < 100; i++ {
+		got := service.pollInterval(models.ChannelConfig{}, nil, time.Now())
+		if got < 95*time.Second || got > 105*time.Second {
+			t.Fatalf("pollInterval() = %v, want 100s ± 5s", got)
+		}
+	}
+
+	if got := service.pollInterval(models.ChannelConfig{Interval: 20}, nil, time.Now()); got < 15*time.Second || got > 25*time.Second {
+		t.Errorf("pollInterval() with channel interval = %v, want 20s ± 5s", got)
+	}
+}
+
+func TestAdaptiveInterval(t *testing.T) {
+	service := NewStreamService(&models.Config{
+		Polling: models.PollingConfig{Interval: 60, Jitter: -1, Adaptive: true, FastInterval: 20, SlowInterval: 600},
+	}, nil)
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.Local)
+	ch := models.ChannelConfig{}
+
+	state := &pollState{}
+	if got := service.pollInterval(ch, state, now); got != 60*time.Second {
+		t.Errorf("interval before first observation = %v, want 60s", got)
+	}
+
+	state.observe(&models.StreamStatus{State: models.StateLive}, now)
+	if got := service.pollInterval(ch, state, now); got != 20*time.Second {
+		t.Errorf("live interval = %v, want 20s", got)
+	}
+
+	// 下播后逐步退避，直到慢速间隔
+	state.observe(&models.StreamStatus{State: models.StateOffline}, now)
+	tests := []struct {
+		offline time.Duration
+		want    time.Duration
+	}{
+		{10 * time.Minute, 60 * time.Second},
+		{time.Hour, 120 * time.Second},
+		{3 * time.Hour, 480 * time.Second},
+		{48 * time.Hour, 600 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := service.pollInterval(ch, state, now.Add(tt.offline)); got != tt.want {
+			t.Errorf("interval after %v offline = %v, want %v", tt.offline, got, tt.want)
+		}
+	}
+
+	// 获取失败不影响直播历史
+	state.observe(&models.StreamStatus{Error: &models.StreamError{Kind: "upstream"}}, now)
+	if state.live || state.goLiveHours[12] != 1 {
+		t.Errorf("error status should not change state: %+v", state)
+	}
+
+	// 单独配置间隔的频道不参与自适应
+	if got := service.pollInterval(models.ChannelConfig{Interval: 45}, state, now); got != 45*time.Second {
+		t.Errorf("channel interval = %v, want 45s", got)
+	}
+}
+
+func TestAdaptiveIntervalNearGoLiveHour(t *testing.T) {
+	service := NewStreamService(&models.Config{
+		Polling: models.PollingConfig{Jitter: -1, Adaptive: true},
+	}, nil)
+	state := &pollState{}
+
+	// 连续两天 20 点开播
+	for day := 1; day <= 2; day++ {
+		start := time.Date(2026, 1, day, 20, 5, 0, 0, time.Local)
+		state.observe(&models.StreamStatus{State: models.StateLive, LiveSince: start.Unix()}, start.Add(time.Minute))
+		// 同一场直播重复观察不重复计数
+		state.observe(&models.StreamStatus{State: models.StateLive, LiveSince: start.Unix()}, start.Add(2*time.Minute))
+		state.observe(&models.StreamStatus{State: models.StateOffline}, start.Add(3*time.Hour))
+	}
+	if state.goLiveHours[20] != 2 {
+		t.Fatalf("goLiveHours[20] = %d, want 2", state.goLiveHours[20])
+	}
+
+	day3 := time.Date(2026, 1, 3, 0, 0, 0, 0, time.Local)
+	if got := service.pollInterval(models.ChannelConfig{}, state, day3.Add(19*time.Hour+40*time.Minute)); got != DefaultFastInterval {
+		t.Errorf("interval before usual go-live hour = %v, want %v", got, DefaultFastInterval)
+	}
+	if got := service.pollInterval(models.ChannelConfig{}, state, day3.Add(14*time.Hour)); got != DefaultSlowInterval {
+		t.Errorf("interval outside usual hours = %v, want %v", got, DefaultSlowInterval)
+	}
+}
+
+func TestNearGoLiveHourMidnightWrap(t *testing.T) {
+	state := &pollState{}
+	state.goLiveHours[0] = goLiveHourThreshold
+
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{day.Add(23*time.Hour + 20*time.Minute), false},
+		{day.Add(23*time.Hour + 40*time.Minute), true}, // 提前 goLiveLead 跨过零点
+		{day.Add(24*time.Hour + 30*time.Minute), true},
+		{day.Add(25*time.Hour + 10*time.Minute), false},
+	}
+	for _, tt := range tests {
+		if got := state.nearGoLiveHour(tt.now); got != tt.want {
+			t.Errorf("nearGoLiveHour(%s) = %v, want %v", tt.now.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestGoLiveHistoryPersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "polling_state.json")
+	provider := &countingProvider{}
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{{Platform: models.PlatformDouyu, ChannelID: "1"}},
+		Polling:  models.PollingConfig{Interval: 60, Jitter: -1, Adaptive: true, StateFile: path},
+	}
+	providers := map[models.Platform]platform.StreamProvider{models.PlatformDouyu: provider}
+	key := pollKey(cfg.Channels[0])
+	now := time.Now()
+
+	service := NewStreamService(cfg, providers)
+	service.loadGoLiveHistory()
+	dueNow(service, now)
+	service.pollDue(context.Background(), now)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("state file not written: %v", err)
+	}
+	var saved map[string][24]int
+	if err := json.Unmarshal(data, &saved); err != nil || saved[key][now.Hour()] != 1 {
+		t.Fatalf("saved state = %s, %v", data, err)
+	}
+
+	// 重启后从文件恢复开播时段统计
+	restarted := NewStreamService(cfg, providers)
+	restarted.loadGoLiveHistory()
+	restarted.pollDue(context.Background(), now)
+	if got := restarted.pollStates[key].goLiveHours[now.Hour()]; got != 1 {
+		t.Errorf("restored goLiveHours[%d] = %d, want 1", now.Hour(), got)
+	}
+}
+
+// toggleProvider 测试用 Provider，按 live 字段返回直播状态
+type toggleProvider struct {
+	live atomic.Bool
+}
+
+func (f *toggleProvider) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	return &models.StreamStatus{ChannelID: channelID, State: models.StateFromLive(f.live.Load()), IsLive: f.live.Load()}, nil
+}
+
+func TestPollingPublishesEvents(t *testing.T) {
+	provider := &toggleProvider{}
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{{Platform: models.PlatformDouyu, ChannelID: "1", Name: "主播"}},
+		Polling:  models.PollingConfig{Interval: 60, Jitter: -1},
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{models.PlatformDouyu: provider})
+	sub, cancel := service.Events().Subscribe(0)
+	defer cancel()
+
+	ctx := context.Background()
+	now := time.Now()
+	dueNow(service, now)
+	service.pollDue(ctx, now)
+	provider.live.Store(true)
+	service.pollDue(ctx, now.Add(time.Minute))
+
+	select {
+	case e := <-sub:
+		if e.Type != events.WentLive || e.Status.Name != "主播" || e.Channel.ChannelID != "1" {
+			t.Errorf("event = %+v", e)
+		}
+	default:
+		t.Fatal("expected went_live event")
+	}
+	if len(sub) != 0 {
+		t.Errorf("unexpected extra events: %d", len(sub))
+	}
+}
@@ -1,11 +1,16 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"live-channels/internal/events"
 	"live-channels/internal/logger"
 	"live-channels/internal/models"
 	"live-channels/internal/platform"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -13,9 +18,19 @@ import (
 
 // StreamService 直播服务
 type StreamService struct {
-	config  *models.Config
-	cache   map[string]cacheItem
-	cacheMu sync.RWMutex
+	config    *models.Config
+	providers map[models.Platform]platform.StreamProvider
+	breakers  map[models.Platform]*platform.Breaker
+	cache     map[string]cacheItem
+	latest    map[string]*models.StreamStatus // 各频道最近一次获取的结果（含旧缓存与错误条目），后台轮询时接口直接读取
+	cacheMu   sync.RWMutex
+
+	bus      *events.Bus
+	detector *events.Detector
+
+	polling       atomic.Bool
+	pollStates    map[string]*pollState // 各频道的轮询状态，仅由轮询 Goroutine 访问
+	goLiveHistory map[string][24]int    // 从 polling.state_file 读取的开播时段统计，仅由轮询 Goroutine 访问
 }
 
 type cacheItem struct {
@@ -23,50 +38,93 @@ type cacheItem struct {
 	timestamp time.Time
 }
 
-// NewStreamService 创建直播服务
-func NewStreamService(config *models.Config) *StreamService {
+// NewStreamService 创建直播服务，providers 为启动时创建的各平台客户端
+func NewStreamService(config *models.Config, providers map[models.Platform]platform.StreamProvider) *StreamService {
+	breakers := make(map[models.Platform]*platform.Breaker, len(providers))
+	for platformType := range providers {
+		breakers[platformType] = platform.NewBreaker(platform.DefaultBreakerThreshold, platform.DefaultBreakerCooldown)
+	}
+
 	return &StreamService{
-		config: config,
-		cache:  make(map[string]cacheItem),
+		config:     config,
+		providers:  providers,
+		breakers:   breakers,
+		cache:      make(map[string]cacheItem),
+		latest:     make(map[string]*models.StreamStatus),
+		bus:        events.NewBus(),
+		detector:   events.NewDetector(config.Events.ViewerMilestones),
+		pollStates: make(map[string]*pollState),
+	}
+}
+
+// Events 返回频道事件总线，订阅者可接收开播、下播、标题变化等事件
+func (s *StreamService) Events() *events.Bus {
+	return s.bus
+}
+
+// BreakerStatuses 返回各平台熔断器的状态
+func (s *StreamService) BreakerStatuses() map[models.Platform]platform.BreakerStatus {
+	statuses := make(map[models.Platform]platform.BreakerStatus, len(s.breakers))
+	for platformType, breaker := range s.breakers {
+		statuses[platformType] = breaker.Status()
 	}
+	return statuses
 }
 
 // GetAllStreamStatus 获取所有直播状态
-func (s *StreamService) GetAllStreamStatus(cacheDuration time.Duration) ([]models.StreamStatus, error) {
-	return s.fetchStreamStatuses(s.config.Channels, cacheDuration), nil
+// 后台轮询运行时直接返回内存中的结果，忽略 cacheDuration
+func (s *StreamService) GetAllStreamStatus(ctx context.Context, cacheDuration time.Duration) ([]models.StreamStatus, error) {
+	return s.readStreamStatuses(ctx, s.config.Channels, cacheDuration), nil
 }
 
 // GetStreamStatusByPlatform 获取指定平台的直播状态
-func (s *StreamService) GetStreamStatusByPlatform(platformType models.Platform, cacheDuration time.Duration) ([]models.StreamStatus, error) {
+func (s *StreamService) GetStreamStatusByPlatform(ctx context.Context, platformType models.Platform, cacheDuration time.Duration) ([]models.StreamStatus, error) {
 	var targetChannels []models.ChannelConfig
 	for _, channel := range s.config.Channels {
 		if channel.Platform == platformType {
 			targetChannels = append(targetChannels, channel)
 		}
 	}
-	return s.fetchStreamStatuses(targetChannels, cacheDuration), nil
+	return s.readStreamStatuses(ctx, targetChannels, cacheDuration), nil
+}
+
+// readStreamStatuses 后台轮询运行时读取内存中的结果，否则按缓存时间实时获取
+func (s *StreamService) readStreamStatuses(ctx context.Context, channels []models.ChannelConfig, cacheDuration time.Duration) []models.StreamStatus {
+	if s.polling.Load() {
+		return s.snapshot(channels)
+	}
+	return s.fetchStreamStatuses(ctx, channels, cacheDuration)
 }
 
 // 默认 Worker 数量
 const DefaultWorkerCount = 10
 
+// 单次刷新所有频道的最长耗时，超时后返回已获取到的结果；后台轮询时为单个频道的最长耗时
+const DefaultFetchTimeout = 8 * time.Second
+
 // fetchStreamStatuses 使用 Worker Pool 并发获取频道列表的直播状态
-func (s *StreamService) fetchStreamStatuses(channels []models.ChannelConfig, cacheDuration time.Duration) []models.StreamStatus {
+func (s *StreamService) fetchStreamStatuses(ctx context.Context, channels []models.ChannelConfig, cacheDuration time.Duration) []models.StreamStatus {
 	// 如果频道数量少于 Worker 数量，就用频道数量，避免启动多余 Goroutine
-	workerCount := DefaultWorkerCount
-	if len(channels) < workerCount {
-		workerCount = len(channels)
-	}
+	workerCount := min(DefaultWorkerCount, len(channels))
 	if workerCount == 0 {
 		return []models.StreamStatus{}
 	}
 
+	// 在此之后写入的缓存视为未过期，本次批量查询的结果也能被 Worker 直接使用
+	freshAfter := time.Now().Add(-cacheDuration)
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultFetchTimeout)
+	defer cancel()
+
+	// 支持批量查询的平台先一次性刷新过期缓存，Worker 随后直接命中缓存
+	s.prefetchBatch(ctx, channels, freshAfter)
+
 	jobs := make(chan models.ChannelConfig, len(channels))
 	results := make(chan *models.StreamStatus, len(channels))
 
 	// 启动 Workers
 	for w := 0; w < workerCount; w++ {
-		go s.worker(jobs, results, cacheDuration)
+		go s.worker(ctx, jobs, results, freshAfter)
 	}
 
 	// 发送任务
@@ -75,12 +133,22 @@ func (s *StreamService) fetchStreamStatuses(channels []models.ChannelConfig, cac
 	}
 	close(jobs)
 
-	// 收集结果
+	// 收集结果，请求取消或超时后不再等待剩余频道
 	var statuses []models.StreamStatus
+collect:
 	for i := 0; i < len(channels); i++ {
-		status := <-results
-		if status != nil {
-			statuses = append(statuses, *status)
+		select {
+		case status := <-results:
+			if status != nil {
+				statuses = append(statuses, *status)
+			}
+		case <-ctx.Done():
+			logger.Warn("Fetch interrupted, returning partial results",
+				zap.Int("received", i),
+				zap.Int("total", len(channels)),
+				zap.Error(ctx.Err()),
+			)
+			break collect
 		}
 	}
 
@@ -90,86 +158,216 @@ func (s *StreamService) fetchStreamStatuses(channels []models.ChannelConfig, cac
 	return statuses
 }
 
-// worker 处理具体的获取任务
-func (s *StreamService) worker(jobs <-chan models.ChannelConfig, results chan<- *models.StreamStatus, cacheDuration time.Duration) {
-	for ch := range jobs {
-		// 1. 尝试从缓存获取
-		cacheKey := string(ch.Platform) + ":" + ch.ChannelID
-		s.cacheMu.RLock()
-		item, found := s.cache[cacheKey]
-		s.cacheMu.RUnlock()
-
-		if found && time.Since(item.timestamp) < cacheDuration {
-			// 缓存命中且未过期
-			logger.Debug("Cache Hit",
-				zap.String("platform", string(ch.Platform)),
-				zap.String("channel_id", ch.ChannelID),
-			)
-			if item.status != nil {
-				// 返回副本以防止外部修改影响缓存
-				copiedStatus := *item.status
-				s.applyConfigOverrides(&copiedStatus, ch)
-				results <- &copiedStatus
-			} else {
-				results <- nil
-			}
-			continue
+// prefetchBatch 对支持批量查询的平台，一次请求刷新所有缓存早于 freshAfter 的频道
+// 批量查询失败或未返回的频道会在 Worker 中单独查询
+func (s *StreamService) prefetchBatch(ctx context.Context, channels []models.ChannelConfig, freshAfter time.Time) {
+	expired := make(map[models.Platform][]models.ChannelConfig)
+	s.cacheMu.RLock()
+	for _, ch := range channels {
+		item, found := s.cache[string(ch.Platform)+":"+ch.Key()]
+		if !found || !item.timestamp.After(freshAfter) {
+			expired[ch.Platform] = append(expired[ch.Platform], ch)
 		}
+	}
+	s.cacheMu.RUnlock()
 
-		// 2. 缓存未命中或过期，从 Provider 获取
-		logger.Debug("Fetching API",
-			zap.String("platform", string(ch.Platform)),
-			zap.String("channel_id", ch.ChannelID),
-		)
-		provider := platform.CreateProvider(ch.Platform)
-		if provider == nil {
-			results <- nil
+	for platformType, targets := range expired {
+		// 只有一个频道时批量查询没有意义
+		if len(targets) < 2 {
+			continue
+		}
+		batchProvider, ok := s.providers[platformType].(platform.BatchStreamProvider)
+		if !ok || !s.allow(platformType) {
 			continue
 		}
 
-		status, err := provider.GetStreamStatus(ch.ChannelID)
+		logger.Debug("Fetching API in batch",
+			zap.String("platform", string(platformType)),
+			zap.Int("channels", len(targets)),
+		)
+		statuses, err := batchProvider.GetStreamStatuses(ctx, targets)
+		s.recordResult(ctx, platformType, err)
 		if err != nil {
-			// 发生错误时，如果缓存中还有（即使过期），优先返回旧缓存作为容错
-			if found && item.status != nil {
-				logger.Warn("Using stale cache due to error",
-					zap.String("platform", string(ch.Platform)),
-					zap.String("channel_id", ch.ChannelID),
-					zap.Error(err),
-				)
-				copiedStatus := *item.status
-				s.applyConfigOverrides(&copiedStatus, ch)
-				results <- &copiedStatus
-				continue
-			}
-			logger.Error("Failed to fetch stream status",
-				zap.String("platform", string(ch.Platform)),
-				zap.String("channel_id", ch.ChannelID),
+			logger.Warn("Batch fetch failed, falling back to single requests",
+				zap.String("platform", string(platformType)),
 				zap.Error(err),
 			)
-			results <- nil
-			continue
 		}
 
-		if status != nil {
-			// 更新缓存（存入原始数据）
-			s.cacheMu.Lock()
-			s.cache[cacheKey] = cacheItem{
+		now := time.Now()
+		s.cacheMu.Lock()
+		for key, status := range statuses {
+			s.cache[string(platformType)+":"+key] = cacheItem{
 				status:    status,
-				timestamp: time.Now(),
+				timestamp: now,
 			}
-			s.cacheMu.Unlock()
-			logger.Debug("Cache Updated",
+		}
+		s.cacheMu.Unlock()
+	}
+}
+
+// worker 处理具体的获取任务
+func (s *StreamService) worker(ctx context.Context, jobs <-chan models.ChannelConfig, results chan<- *models.StreamStatus, freshAfter time.Time) {
+	for ch := range jobs {
+		status := s.fetchChannel(ctx, ch, freshAfter)
+		s.storeLatest(ch, status)
+		results <- status
+	}
+}
+
+// fetchChannel 获取单个频道的直播状态，优先使用 freshAfter 之后写入的缓存
+func (s *StreamService) fetchChannel(ctx context.Context, ch models.ChannelConfig, freshAfter time.Time) *models.StreamStatus {
+	// 1. 尝试从缓存获取
+	cacheKey := string(ch.Platform) + ":" + ch.Key()
+	s.cacheMu.RLock()
+	item, found := s.cache[cacheKey]
+	s.cacheMu.RUnlock()
+
+	if found && item.timestamp.After(freshAfter) {
+		// 缓存命中且未过期
+		logger.Debug("Cache Hit",
+			zap.String("platform", string(ch.Platform)),
+			zap.String("channel_id", ch.Key()),
+		)
+		if item.status == nil {
+			return nil
+		}
+		// 返回副本以防止外部修改影响缓存
+		copiedStatus := *item.status
+		s.applyConfigOverrides(&copiedStatus, ch)
+		return &copiedStatus
+	}
+
+	// 2. 缓存未命中或过期，从 Provider 获取
+	logger.Debug("Fetching API",
+		zap.String("platform", string(ch.Platform)),
+		zap.String("channel_id", ch.Key()),
+	)
+	provider := s.providers[ch.Platform]
+	if provider == nil {
+		return s.errorStatus(ch, "unsupported", fmt.Sprintf("platform %s is not supported", ch.Platform))
+	}
+
+	// 排队期间已超时或被取消的频道不再请求上游，也不计入熔断器
+	if err := ctx.Err(); err != nil {
+		if found && item.status != nil {
+			return s.staleStatus(item, ch)
+		}
+		return s.errorStatus(ch, platform.ErrorKind(err), err.Error())
+	}
+
+	// 平台熔断中，不请求上游，直接返回旧缓存
+	if !s.allow(ch.Platform) {
+		logger.Debug("Circuit open, skipping fetch",
+			zap.String("platform", string(ch.Platform)),
+			zap.String("channel_id", ch.Key()),
+		)
+		if found && item.status != nil {
+			return s.staleStatus(item, ch)
+		}
+		return s.errorStatus(ch, "circuit_open", fmt.Sprintf("%s is temporarily paused after repeated failures", ch.Platform))
+	}
+
+	status, err := s.getStreamStatus(ctx, provider, ch)
+	s.recordResult(ctx, ch.Platform, err)
+	if err != nil {
+		// 发生错误时，如果缓存中还有（即使过期），优先返回旧缓存作为容错
+		if found && item.status != nil {
+			logger.Warn("Using stale cache due to error",
 				zap.String("platform", string(ch.Platform)),
-				zap.String("channel_id", ch.ChannelID),
+				zap.String("channel_id", ch.Key()),
+				zap.Error(err),
 			)
+			return s.staleStatus(item, ch)
+		}
+		logger.Error("Failed to fetch stream status",
+			zap.String("platform", string(ch.Platform)),
+			zap.String("channel_id", ch.Key()),
+			zap.Error(err),
+		)
+		return s.errorStatus(ch, platform.ErrorKind(err), err.Error())
+	}
 
-			// 在返回前应用配置覆盖
-			s.applyConfigOverrides(status, ch)
+	if status != nil {
+		// 更新缓存（存入原始数据）
+		s.cacheMu.Lock()
+		s.cache[cacheKey] = cacheItem{
+			status:    status,
+			timestamp: time.Now(),
 		}
-		results <- status
+		s.cacheMu.Unlock()
+		logger.Debug("Cache Updated",
+			zap.String("platform", string(ch.Platform)),
+			zap.String("channel_id", ch.Key()),
+		)
+
+		// 返回副本并应用配置覆盖，缓存中保留原始数据
+		copiedStatus := *status
+		s.applyConfigOverrides(&copiedStatus, ch)
+		return &copiedStatus
+	}
+	return nil
+}
+
+// staleStatus 返回标记为过期的缓存副本
+func (s *StreamService) staleStatus(item cacheItem, ch models.ChannelConfig) *models.StreamStatus {
+	copiedStatus := *item.status
+	copiedStatus.Stale = true
+	s.applyConfigOverrides(&copiedStatus, ch)
+	return &copiedStatus
+}
+
+// errorStatus 为获取失败的频道生成带错误信息的状态，使其仍出现在结果中
+func (s *StreamService) errorStatus(ch models.ChannelConfig, kind, message string) *models.StreamStatus {
+	status := &models.StreamStatus{
+		ChannelID: ch.Key(),
+		Name:      ch.Key(),
+		Platform:  string(ch.Platform),
+		State:     models.StateOffline,
+		UpdatedAt: time.Now().Unix(),
+		Error:     &models.StreamError{Kind: kind, Message: message},
+	}
+	s.applyConfigOverrides(status, ch)
+	return status
+}
+
+// allow 返回平台熔断器是否允许发起请求
+func (s *StreamService) allow(platformType models.Platform) bool {
+	breaker := s.breakers[platformType]
+	return breaker == nil || breaker.Allow()
+}
+
+// recordResult 将请求结果计入平台熔断器
+// 调用方取消导致的失败不计入；超时（包括服务自身的 DefaultFetchTimeout）说明平台无响应，计为失败；
+// 频道不存在说明平台本身可用，按成功处理
+func (s *StreamService) recordResult(ctx context.Context, platformType models.Platform, err error) {
+	breaker := s.breakers[platformType]
+	if breaker == nil {
+		return
+	}
+	switch {
+	case err == nil, errors.Is(err, platform.ErrChannelNotFound):
+		breaker.Success()
+	case errors.Is(err, context.Canceled), errors.Is(ctx.Err(), context.Canceled), errors.Is(err, platform.ErrNotConfigured):
+		// 请求被调用方取消或因本地配置缺失未发出，不代表平台异常
+		breaker.Release()
+	default:
+		breaker.Failure()
 	}
 }
 
+// getStreamStatus 根据频道配置选择按房间号或按 UID 查询
+func (s *StreamService) getStreamStatus(ctx context.Context, provider platform.StreamProvider, ch models.ChannelConfig) (*models.StreamStatus, error) {
+	if ch.ChannelID == "" && ch.UID != "" {
+		uidProvider, ok := provider.(platform.UIDStreamProvider)
+		if !ok {
+			return nil, fmt.Errorf("platform %s does not support uid lookup: %w", ch.Platform, platform.ErrNotConfigured)
+		}
+		return uidProvider.GetStreamStatusByUID(ctx, ch.UID)
+	}
+	return provider.GetStreamStatus(ctx, ch.ChannelID)
+}
+
 // applyConfigOverrides 应用配置文件中的覆盖项
 func (s *StreamService) applyConfigOverrides(status *models.StreamStatus, ch models.ChannelConfig) {
 	if ch.Name != "" {
@@ -178,15 +376,38 @@ func (s *StreamService) applyConfigOverrides(status *models.StreamStatus, ch mod
 }
 
 // sortStreamStatus 对直播状态进行排序
-// 排序规则：1. 在直播的在前，不在直播的在后；2. 同状态下按观众数量多的在前
+// 排序规则：1. 直播中在前，轮播次之，未开播在后，获取失败的最后；2. 同状态下按观众数量多的在前
 func (s *StreamService) sortStreamStatus(statuses []models.StreamStatus) {
 	sort.Slice(statuses, func(i, j int) bool {
-		// 首先按直播状态排序：IsLive=true 的排在前面
-		if statuses[i].IsLive != statuses[j].IsLive {
-			return statuses[i].IsLive // true > false
+		// 首先按直播状态排序
+		rankI, rankJ := stateRank(statuses[i]), stateRank(statuses[j])
+		if rankI != rankJ {
+			return rankI < rankJ
 		}
 
 		// 如果直播状态相同，按观众数量排序：数量多的在前
 		return statuses[i].Viewers > statuses[j].Viewers
 	})
 }
+
+// stateRank 返回直播状态的排序权重，越小越靠前，获取失败的频道排在最后
+func stateRank(status models.StreamStatus) int {
+	if status.Error != nil {
+		return 3
+	}
+
+	switch status.State {
+	case models.StateLive:
+		return 0
+	case models.StateReplay:
+		return 1
+	case models.StateOffline:
+		return 2
+	}
+
+	// 未设置 State 时按 IsLive 判断
+	if status.IsLive {
+		return 0
+	}
+	return 2
+}
@@ -1,10 +1,51 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"live-channels/internal/models"
+	"live-channels/internal/platform"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
+// fakeProvider 测试用 Provider，只支持按房间号查询
+type fakeProvider struct{}
+
+func (f *fakeProvider) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	if channelID == "" {
+		return nil, errors.New("empty channel id")
+	}
+	return &models.StreamStatus{ChannelID: channelID}, nil
+}
+
+// fakeUIDProvider 测试用 Provider，同时支持按 UID 查询
+type fakeUIDProvider struct {
+	fakeProvider
+}
+
+func (f *fakeUIDProvider) GetStreamStatusByUID(ctx context.Context, uid string) (*models.StreamStatus, error) {
+	return &models.StreamStatus{ChannelID: "room-of-" + uid}, nil
+}
+
+// failingProvider 测试用 Provider，在 fail 为 true 时返回错误并记录调用次数
+type failingProvider struct {
+	fail  bool
+	calls int
+}
+
+func (f *failingProvider) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	f.calls++
+	if f.fail {
+		return nil, errors.New("upstream error")
+	}
+	return &models.StreamStatus{ChannelID: channelID}, nil
+}
+
 func TestNewStreamService(t *testing.T) {
 	cfg := &models.Config{
 		Channels: []models.ChannelConfig{
@@ -16,7 +57,7 @@ func TestNewStreamService(t *testing.T) {
 		},
 	}
 
-	service := NewStreamService(cfg)
+	service := NewStreamService(cfg, nil)
 	if service == nil {
 		t.Fatal("NewStreamService returned nil")
 	}
@@ -27,7 +68,7 @@ func TestNewStreamService(t *testing.T) {
 }
 
 func TestApplyConfigOverrides(t *testing.T) {
-	service := NewStreamService(&models.Config{})
+	service := NewStreamService(&models.Config{}, nil)
 	status := &models.StreamStatus{
 		Name: "Original Name",
 	}
@@ -51,7 +92,7 @@ func TestApplyConfigOverrides(t *testing.T) {
 }
 
 func TestSortStreamStatus(t *testing.T) {
-	service := NewStreamService(&models.Config{})
+	service := NewStreamService(&models.Config{}, nil)
 	statuses := []models.StreamStatus{
 		{Name: "A", IsLive: false, Viewers: 100},
 		{Name: "B", IsLive: true, Viewers: 50},
@@ -69,3 +110,261 @@ func TestSortStreamStatus(t *testing.T) {
 		}
 	}
 }
+
+func TestGetStreamStatusByUID(t *testing.T) {
+	service := NewStreamService(&models.Config{}, nil)
+
+	status, err := service.getStreamStatus(context.Background(), &fakeUIDProvider{}, models.ChannelConfig{UID: "42"})
+	if err != nil || status.ChannelID != "room-of-42" {
+		t.Errorf("getStreamStatus() by uid = %+v, %v", status, err)
+	}
+
+	status, err = service.getStreamStatus(context.Background(), &fakeUIDProvider{}, models.ChannelConfig{ChannelID: "7", UID: "42"})
+	if err != nil || status.ChannelID != "7" {
+		t.Errorf("getStreamStatus() should prefer channel_id, got %+v, %v", status, err)
+	}
+
+	if _, err := service.getStreamStatus(context.Background(), &fakeProvider{}, models.ChannelConfig{UID: "42"}); err == nil {
+		t.Errorf("getStreamStatus() by uid on unsupported provider should return error")
+	}
+}
+
+func TestSortStreamStatusWithReplay(t *testing.T) {
+	service := NewStreamService(&models.Config{}, nil)
+	statuses := []models.StreamStatus{
+		{Name: "A", State: models.StateOffline, Viewers: 1000},
+		{Name: "B", State: models.StateReplay, Viewers: 10},
+		{Name: "C", State: models.StateLive, IsLive: true, Viewers: 5},
+		{Name: "D", State: models.StateReplay, Viewers: 500},
+	}
+
+	service.sortStreamStatus(statuses)
+
+	expected := []string{"C", "D", "B", "A"}
+	for i, name := range expected {
+		if statuses[i].Name != name {
+			t.Errorf("At index %d: expected %s, got %s", i, name, statuses[i].Name)
+		}
+	}
+}
+
+func TestGetAllStreamStatusUsesInjectedProviders(t *testing.T) {
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{
+			{Platform: models.PlatformBilibili, UID: "42"},
+			{Platform: models.PlatformHuya, ChannelID: "7", Name: "override"},
+			{Platform: models.PlatformDouyu, ChannelID: "9"},
+		},
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{
+		models.PlatformBilibili: &fakeUIDProvider{},
+		models.PlatformHuya:     &fakeProvider{},
+	})
+
+	statuses, err := service.GetAllStreamStatus(context.Background(), time.Minute)
+	if err != nil {
+		t.Fatalf("GetAllStreamStatus() error = %v", err)
+	}
+
+	if len(statuses) != 3 {
+		t.Fatalf("GetAllStreamStatus() returned %d statuses, want 3", len(statuses))
+	}
+	got := map[string]string{}
+	for _, status := range statuses {
+		got[status.ChannelID] = status.Name
+	}
+	if _, ok := got["room-of-42"]; !ok || got["7"] != "override" {
+		t.Errorf("GetAllStreamStatus() = %+v", statuses)
+	}
+
+	// 未注入 Provider 的平台返回错误条目，并排在最后
+	if last := statuses[2]; last.ChannelID != "9" || last.Error == nil || last.Error.Kind != "unsupported" {
+		t.Errorf("last status = %+v, want unsupported error entry", last)
+	}
+}
+
+func TestCircuitBreakerServesStaleCache(t *testing.T) {
+	provider := &failingProvider{}
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{{Platform: models.PlatformDouyu, ChannelID: "9"}},
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{
+		models.PlatformDouyu: provider,
+	})
+
+	statuses, _ := service.GetAllStreamStatus(context.Background(), 0)
+	if len(statuses) != 1 || statuses[0].Stale {
+		t.Fatalf("first fetch = %+v, want fresh status", statuses)
+	}
+
+	provider.fail = true
+	for i := 0; i < platform.DefaultBreakerThreshold; i++ {
+		statuses, _ = service.GetAllStreamStatus(context.Background(), 0)
+		if len(statuses) != 1 || !statuses[0].Stale {
+			t.Fatalf("fetch after error = %+v, want stale status", statuses)
+		}
+	}
+	if got := service.BreakerStatuses()[models.PlatformDouyu].State; got != platform.BreakerOpen {
+		t.Fatalf("breaker state = %q, want open", got)
+	}
+
+	// 熔断后不再请求上游，继续返回旧缓存
+	calls := provider.calls
+	statuses, _ = service.GetAllStreamStatus(context.Background(), 0)
+	if provider.calls != calls {
+		t.Errorf("provider called %d times while circuit open", provider.calls-calls)
+	}
+	if len(statuses) != 1 || !statuses[0].Stale {
+		t.Errorf("fetch while circuit open = %+v, want stale status", statuses)
+	}
+}
+
+// notFoundProvider 测试用 Provider，总是返回频道不存在
+type notFoundProvider struct{}
+
+func (f *notFoundProvider) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	return nil, fmt.Errorf("room %s: %w", channelID, platform.ErrChannelNotFound)
+}
+
+func TestFailedChannelsReturnErrorEntries(t *testing.T) {
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{{Platform: models.PlatformHuya, ChannelID: "typo", Name: "主播"}},
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{
+		models.PlatformHuya: &notFoundProvider{},
+	})
+
+	for i := 0; i < platform.DefaultBreakerThreshold+1; i++ {
+		statuses, _ := service.GetAllStreamStatus(context.Background(), 0)
+		if len(statuses) != 1 || statuses[0].Error == nil || statuses[0].Error.Kind != "not_found" {
+			t.Fatalf("GetAllStreamStatus() = %+v, want not_found error entry", statuses)
+		}
+		if statuses[0].Name != "主播" || statuses[0].ChannelID != "typo" {
+			t.Errorf("error entry Name/ChannelID = %q/%q", statuses[0].Name, statuses[0].ChannelID)
+		}
+	}
+
+	// 频道不存在不应触发熔断
+	if got := service.BreakerStatuses()[models.PlatformHuya].State; got != platform.BreakerClosed {
+		t.Errorf("breaker state = %q, want closed", got)
+	}
+}
+
+// notConfiguredProvider 测试用 Provider，总是返回缺少凭据
+type notConfiguredProvider struct{}
+
+func (f *notConfiguredProvider) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	return nil, fmt.Errorf("twitch client credentials: %w", platform.ErrNotConfigured)
+}
+
+func TestNotConfiguredDoesNotTripBreaker(t *testing.T) {
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{{Platform: models.PlatformTwitch, ChannelID: "streamer"}},
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{
+		models.PlatformTwitch: &notConfiguredProvider{},
+	})
+
+	for i := 0; i < platform.DefaultBreakerThreshold+1; i++ {
+		statuses, _ := service.GetAllStreamStatus(context.Background(), 0)
+		if len(statuses) != 1 || statuses[0].Error == nil || statuses[0].Error.Kind != "config" {
+			t.Fatalf("GetAllStreamStatus() = %+v, want config error entry", statuses)
+		}
+	}
+	if got := service.BreakerStatuses()[models.PlatformTwitch].State; got != platform.BreakerClosed {
+		t.Errorf("breaker state = %q, want closed", got)
+	}
+}
+
+// blockingProvider 测试用 Provider，模拟无响应的平台，一直阻塞到 ctx 结束
+type blockingProvider struct{}
+
+func (f *blockingProvider) GetStreamStatus(ctx context.Context, channelID string) (*models.StreamStatus, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("failed to fetch room: %w: %w", platform.ErrUpstream, ctx.Err())
+}
+
+func TestTimeoutTripsBreaker(t *testing.T) {
+	ch := models.ChannelConfig{Platform: models.PlatformDouyu, ChannelID: "9999"}
+	service := NewStreamService(&models.Config{Channels: []models.ChannelConfig{ch}}, map[models.Platform]platform.StreamProvider{
+		models.PlatformDouyu: &blockingProvider{},
+	})
+
+	// 调用方取消不计入熔断器
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	service.fetchChannel(ctx, ch, time.Now())
+	if got := service.BreakerStatuses()[models.PlatformDouyu].Failures; got != 0 {
+		t.Fatalf("failures after cancel = %d, want 0", got)
+	}
+
+	// 平台一直无响应直到超时，连续超时后熔断
+	for i := 0; i < platform.DefaultBreakerThreshold; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		status := service.fetchChannel(ctx, ch, time.Now())
+		cancel()
+		if status.Error == nil || status.Error.Kind != "timeout" {
+			t.Fatalf("status = %+v, want timeout error entry", status)
+		}
+	}
+	if got := service.BreakerStatuses()[models.PlatformDouyu].State; got != platform.BreakerOpen {
+		t.Errorf("breaker state = %q, want open", got)
+	}
+	status := service.fetchChannel(context.Background(), ch, time.Now())
+	if status.Error == nil || status.Error.Kind != "circuit_open" {
+		t.Errorf("status after breaker opened = %+v, want circuit_open", status)
+	}
+}
+
+func TestFetchUsesBilibiliBatch(t *testing.T) {
+	var batches, singles atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/room/v1/Room/room_init":
+			id := r.URL.Query().Get("id")
+			w.Write([]byte(`{"code":0,"data":{"room_id":` + id + `,"uid":` + id + `0}}`))
+		case "/room/v1/Room/get_status_info_by_uids":
+			batches.Add(1)
+			// 批量结果缺少房间 2 的主播
+			w.Write([]byte(`{"code":0,"data":{"10":{"room_id":1,"uid":10,"live_status":1,"uname":"批量一"},"30":{"room_id":3,"uid":30,"live_status":0,"uname":"批量三"}}}`))
+		case "/room/v1/Room/get_info":
+			singles.Add(1)
+			w.Write([]byte(`{"code":0,"data":{"live_status":1,"room_id":2}}`))
+		case "/live_user/v1/UserInfo/get_anchor_in_room":
+			w.Write([]byte(`{"code":0,"data":{"info":{"uname":"单独二"}}}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &models.Config{
+		Channels: []models.ChannelConfig{
+			{Platform: models.PlatformBilibili, ChannelID: "1"},
+			{Platform: models.PlatformBilibili, ChannelID: "2"},
+			{Platform: models.PlatformBilibili, ChannelID: "3"},
+		},
+	}
+	service := NewStreamService(cfg, map[models.Platform]platform.StreamProvider{
+		models.PlatformBilibili: platform.NewBilibiliClientWithBaseURL(server.URL),
+	})
+
+	statuses, err := service.GetAllStreamStatus(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("GetAllStreamStatus() error = %v", err)
+	}
+	names := map[string]string{}
+	for _, status := range statuses {
+		names[status.ChannelID] = status.Name
+	}
+	if names["1"] != "批量一" || names["3"] != "批量三" || names["2"] != "单独二" {
+		t.Errorf("names = %v", names)
+	}
+	// 批量结果覆盖的频道不再单独请求，只有缺失的频道回退到单独查询
+	if batches.Load() != 1 || singles.Load() != 1 {
+		t.Errorf("batch/single calls = %d/%d, want 1/1", batches.Load(), singles.Load())
+	}
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"live-channels/internal/api"
 	"live-channels/internal/config"
 	"live-channels/internal/logger"
+	"live-channels/internal/notify"
 	"live-channels/internal/platform"
+	"live-channels/internal/service"
 	"os"
 	"strings"
 
@@ -70,8 +73,25 @@ func main() {
 	}
 	platform.SetUserAgent(ua)
 
-	// 4. 启动 API 服务器
-	router := api.SetupRouter(cfg)
+	// 4. 创建各平台客户端与直播服务（Twitch、YouTube 凭据随平台配置传入）
+	providers, err := platform.NewProviders(cfg.Platforms)
+	if err != nil {
+		logger.Fatal("Failed to create platform providers", zap.Error(err))
+	}
+	streamService := service.NewStreamService(cfg, providers)
+
+	// 4.1 启动事件通知，需在轮询前订阅以免漏掉事件
+	notifiers, err := notify.FromConfig(cfg.Notifications)
+	if err != nil {
+		logger.Fatal("Failed to create notifiers", zap.Error(err))
+	}
+	notify.NewDispatcher(notifiers...).Start(context.Background(), streamService.Events())
+
+	// 4.2 启动后台轮询，接口直接读取内存中的结果
+	streamService.StartPolling(context.Background())
+
+	// 5. 启动 API 服务器
+	router := api.SetupRouter(streamService)
 
 	logger.Info("Starting server",
 		zap.String("port", port),